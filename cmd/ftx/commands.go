@@ -0,0 +1,192 @@
+package main
+
+import (
+	"flag"
+	"strconv"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx"
+)
+
+func marketsCmd(fs *flag.FlagSet) execFunc {
+	return func(c *ftx.Client, args []string) (interface{}, *table, error) {
+		if len(args) != 0 {
+			return nil, nil, errUsage
+		}
+		markets, err := c.Markets.All()
+		if err != nil {
+			return nil, nil, err
+		}
+		return markets, marketTable(markets...), nil
+	}
+}
+
+func marketCmd(fs *flag.FlagSet) execFunc {
+	return func(c *ftx.Client, args []string) (interface{}, *table, error) {
+		if len(args) != 1 {
+			return nil, nil, errUsage
+		}
+		market, err := c.Markets.Get(args[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		return market, marketTable(*market), nil
+	}
+}
+
+func marketTable(markets ...ftx.Market) *table {
+	t := newTable("NAME", "TYPE", "UNDERLYING", "BID", "ASK", "LAST", "PRICE INCREMENT", "SIZE INCREMENT", "ENABLED")
+	for _, m := range markets {
+		t.add(m.Name, m.Type, m.Underlying, float(m.Bid), float(m.Ask), float(m.Last),
+			float(m.PriceIncrement), float(m.SizeIncrement), strconv.FormatBool(m.Enabled))
+	}
+	return t
+}
+
+func orderBookCmd(fs *flag.FlagSet) execFunc {
+	depth := fs.Int("depth", 20, "number of levels of each side, up to 100")
+
+	return func(c *ftx.Client, args []string) (interface{}, *table, error) {
+		if len(args) != 1 {
+			return nil, nil, errUsage
+		}
+		book, err := c.Markets.GetOrderBook(args[0], &ftx.GetOrderBookOptions{Depth: *depth})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Asks from the highest down to the spread, then bids.
+		t := newTable("SIDE", "PRICE", "SIZE")
+		for i := len(book.Asks) - 1; i >= 0; i-- {
+			t.add("ask", float(book.Asks[i][0]), float(book.Asks[i][1]))
+		}
+		for _, l := range book.Bids {
+			t.add("bid", float(l[0]), float(l[1]))
+		}
+		return book, t, nil
+	}
+}
+
+func tradesCmd(fs *flag.FlagSet) execFunc {
+	limit := fs.Int("limit", 20, "number of trades")
+
+	return func(c *ftx.Client, args []string) (interface{}, *table, error) {
+		if len(args) != 1 {
+			return nil, nil, errUsage
+		}
+		trades, err := c.Markets.GetTrades(args[0], &ftx.GetTradesOptions{Limit: *limit})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		t := newTable("ID", "TIME", "SIDE", "PRICE", "SIZE", "LIQUIDATION")
+		for _, tr := range trades {
+			t.add(strconv.Itoa(tr.ID), tr.Time.UTC().Format(time.RFC3339Nano), tr.Side,
+				float(tr.Price), float(tr.Size), strconv.FormatBool(tr.Liquidation))
+		}
+		return trades, t, nil
+	}
+}
+
+func candlesCmd(fs *flag.FlagSet) execFunc {
+	resolution := fs.Int("resolution", ftx.Resolution1h, "window length in seconds: 15, 60, 300, 900, 3600, 14400 or a multiple of 86400")
+	limit := fs.Int("limit", 24, "number of candles")
+
+	return func(c *ftx.Client, args []string) (interface{}, *table, error) {
+		if len(args) != 1 {
+			return nil, nil, errUsage
+		}
+		candles, err := c.Markets.GetHistoricalPrices(args[0], &ftx.GetHistoricalPrices{Resolution: *resolution, Limit: *limit})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		t := newTable("START", "OPEN", "HIGH", "LOW", "CLOSE", "VOLUME")
+		for _, cd := range candles {
+			t.add(cd.StartTime.UTC().Format(time.RFC3339), float(cd.Open), float(cd.High),
+				float(cd.Low), float(cd.Close), float(cd.Volume))
+		}
+		return candles, t, nil
+	}
+}
+
+func accountCmd(fs *flag.FlagSet) execFunc {
+	return func(c *ftx.Client, args []string) (interface{}, *table, error) {
+		if len(args) != 0 {
+			return nil, nil, errUsage
+		}
+		a, err := c.Accounts.GetInformation()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		t := newTable("FIELD", "VALUE")
+		t.add("username", a.Username)
+		t.add("collateral", float(a.Collateral))
+		t.add("free collateral", float(a.FreeCollateral))
+		t.add("total account value", float(a.TotalAccountValue))
+		t.add("total position size", float(a.TotalPositionSize))
+		t.add("leverage", float(a.Leverage))
+		t.add("margin fraction", float(a.MarginFraction))
+		t.add("open margin fraction", float(a.OpenMarginFraction))
+		t.add("initial margin requirement", float(a.InitialMarginRequirement))
+		t.add("maintenance margin requirement", float(a.MaintenanceMarginRequirement))
+		t.add("maker fee", float(a.MakerFee))
+		t.add("taker fee", float(a.TakerFee))
+		t.add("liquidating", strconv.FormatBool(a.Liquidating))
+		return a, t, nil
+	}
+}
+
+func positionsCmd(fs *flag.FlagSet) execFunc {
+	future := fs.String("future", "", "only show the position of this future")
+
+	return func(c *ftx.Client, args []string) (interface{}, *table, error) {
+		if len(args) != 0 {
+			return nil, nil, errUsage
+		}
+		positions, err := c.Accounts.GetPositionsWithOptions(&ftx.GetPositionsOptions{Future: *future})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		t := newTable("FUTURE", "SIDE", "SIZE", "NET SIZE", "ENTRY PRICE", "EST. LIQUIDATION PRICE", "UNREALIZED PNL", "REALIZED PNL", "COLLATERAL USED")
+		for _, p := range positions {
+			t.add(p.Future, p.Side, float(p.Size), float(p.NetSize), float(p.EntryPrice), float(p.EstimatedLiquidationPrice),
+				float(p.UnrealizedPnl), float(p.RealizedPnl), float(p.CollateralUsed))
+		}
+		return positions, t, nil
+	}
+}
+
+func leverageCmd(fs *flag.FlagSet) execFunc {
+	return func(c *ftx.Client, args []string) (interface{}, *table, error) {
+		switch {
+		case len(args) == 0:
+		case len(args) == 2 && args[0] == "set":
+			x, err := strconv.Atoi(args[1])
+			if err != nil {
+				return nil, nil, errUsage
+			}
+			if err := c.Accounts.SetLeverage(x); err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, errUsage
+		}
+
+		a, err := c.Accounts.GetInformation()
+		if err != nil {
+			return nil, nil, err
+		}
+		t := newTable("LEVERAGE")
+		t.add(float(a.Leverage))
+		return struct {
+			Leverage float64 `json:"leverage"`
+		}{a.Leverage}, t, nil
+	}
+}
+
+func float(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,160 @@
+// Command ftx prints market data and account information of FTX.
+//
+// Usage:
+//
+//	ftx [flags] <command> [arguments]
+//
+// Credentials are read from the -key, -secret and -subaccount flags, or the
+// FTX_API_KEY, FTX_API_SECRET and FTX_SUBACCOUNT environment variables.
+// Output is a table by default, or JSON or CSV with -o.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/cloudingcity/go-ftx/ftx"
+)
+
+// command defines its flags in setup, which returns the function running it.
+type command struct {
+	usage string
+	setup func(fs *flag.FlagSet) execFunc
+}
+
+// execFunc runs a command with the positional arguments. It returns the result
+// for JSON output and its table for the other formats.
+type execFunc func(c *ftx.Client, args []string) (interface{}, *table, error)
+
+var commands = map[string]command{
+	"markets":   {"markets", marketsCmd},
+	"market":    {"market <name>", marketCmd},
+	"orderbook": {"orderbook <name> [-depth n]", orderBookCmd},
+	"trades":    {"trades <name> [-limit n]", tradesCmd},
+	"candles":   {"candles <name> [-resolution seconds] [-limit n]", candlesCmd},
+	"account":   {"account", accountCmd},
+	"positions": {"positions [-future name]", positionsCmd},
+	"leverage":  {"leverage [set <x>]", leverageCmd},
+}
+
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr, os.Getenv))
+}
+
+// run runs the command line args and returns the exit code. opts are applied
+// after the options of the flags.
+func run(args []string, stdout, stderr io.Writer, getenv func(string) string, opts ...ftx.Option) int {
+	fs := flag.NewFlagSet("ftx", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	// Defaults are read from the environment after parsing so that usage doesn't print them.
+	key := fs.String("key", "", "API key, defaults to $FTX_API_KEY")
+	secret := fs.String("secret", "", "API secret, defaults to $FTX_API_SECRET")
+	subaccount := fs.String("subaccount", "", "subaccount, defaults to $FTX_SUBACCOUNT")
+	format := fs.String("o", formatTable, "output format: table, json or csv")
+	fs.Usage = func() { usage(fs) }
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	name := fs.Arg(0)
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "ftx: unknown command %q\n", name)
+		fs.Usage()
+		return 2
+	}
+
+	cmdFS := flag.NewFlagSet(name, flag.ContinueOnError)
+	cmdFS.SetOutput(stderr)
+	exec := cmd.setup(cmdFS)
+	// Global flags may follow the command as well.
+	fs.VisitAll(func(f *flag.Flag) { cmdFS.Var(f.Value, f.Name, f.Usage) })
+	cmdFS.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: ftx %s\n", cmd.usage)
+		cmdFS.PrintDefaults()
+	}
+	pos, err := parseInterspersed(cmdFS, fs.Args()[1:])
+	if err != nil {
+		return 2
+	}
+	if !validFormat(*format) {
+		fmt.Fprintf(stderr, "ftx: unknown output format %q\n", *format)
+		cmdFS.Usage()
+		return 2
+	}
+
+	for p, env := range map[*string]string{key: "FTX_API_KEY", secret: "FTX_API_SECRET", subaccount: "FTX_SUBACCOUNT"} {
+		if *p == "" {
+			*p = getenv(env)
+		}
+	}
+
+	var clientOpts []ftx.Option
+	if *key != "" || *secret != "" {
+		clientOpts = append(clientOpts, ftx.WithAuth(*key, *secret))
+	}
+	if *subaccount != "" {
+		clientOpts = append(clientOpts, ftx.WithSubaccount(*subaccount))
+	}
+	client := ftx.New(append(clientOpts, opts...)...)
+
+	v, t, err := exec(client, pos)
+	if err == errUsage {
+		cmdFS.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "ftx %s: %v\n", name, err)
+		return 1
+	}
+	if err := write(stdout, *format, v, t); err != nil {
+		fmt.Fprintf(stderr, "ftx: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed parses flags placed before, between or after the
+// positional arguments, which it returns.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return pos, nil
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: ftx [flags] <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	fs.PrintDefaults()
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/cloudingcity/go-ftx/ftx"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// routes answers requests by path with a result, recording the requests.
+type routes struct {
+	results map[string]string
+	last    *fasthttp.Request
+	byPath  map[string]*fasthttp.Request
+}
+
+func (r *routes) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	path := string(req.URI().Path())
+	r.last = &fasthttp.Request{}
+	req.CopyTo(r.last)
+	if r.byPath == nil {
+		r.byPath = make(map[string]*fasthttp.Request)
+	}
+	r.byPath[path] = r.last
+
+	result, ok := r.results[path]
+	if !ok {
+		resp.SetStatusCode(fasthttp.StatusNotFound)
+		resp.SetBodyString(`{"success":false,"error":"Not found"}`)
+		return nil
+	}
+	resp.SetBodyString(`{"success":true,"result":` + result + `}`)
+	return nil
+}
+
+func runCLI(r *routes, env map[string]string, args ...string) (code int, stdout, stderr string) {
+	var out, errOut bytes.Buffer
+	getenv := func(k string) string { return env[k] }
+	code = run(args, &out, &errOut, getenv, ftx.WithTransport(r))
+	return code, out.String(), errOut.String()
+}
+
+func TestRun_Markets(t *testing.T) {
+	r := &routes{results: map[string]string{
+		"/api/markets": `[{"name":"BTC-PERP","type":"future","underlying":"BTC","bid":47091,"ask":47095.5,"last":47093,"priceIncrement":0.5,"sizeIncrement":0.0001,"enabled":true},{"name":"ETH/USD","type":"spot","bid":3000.1,"ask":3000.2,"last":3000.1,"priceIncrement":0.1,"sizeIncrement":0.001,"enabled":true}]`,
+	}}
+
+	t.Run("table", func(t *testing.T) {
+		code, out, _ := runCLI(r, nil, "markets")
+
+		assert.Equal(t, 0, code)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, []string{"NAME", "TYPE", "UNDERLYING", "BID", "ASK", "LAST", "PRICE", "INCREMENT", "SIZE", "INCREMENT", "ENABLED"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"BTC-PERP", "future", "BTC", "47091", "47095.5", "47093", "0.5", "0.0001", "true"}, strings.Fields(lines[1]))
+		// Columns are aligned.
+		assert.Equal(t, strings.Index(lines[0], "BID"), strings.Index(lines[2], "3000.1"))
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		code, out, _ := runCLI(r, nil, "-o", "csv", "markets")
+
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "NAME,TYPE,UNDERLYING,BID,ASK,LAST,PRICE INCREMENT,SIZE INCREMENT,ENABLED\n"+
+			"BTC-PERP,future,BTC,47091,47095.5,47093,0.5,0.0001,true\n"+
+			"ETH/USD,spot,,3000.1,3000.2,3000.1,0.1,0.001,true\n", out)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		code, out, _ := runCLI(r, nil, "markets", "-o", "json")
+
+		assert.Equal(t, 0, code)
+		assert.Contains(t, out, `"name": "BTC-PERP"`)
+		assert.Contains(t, out, `"priceIncrement": 0.5`)
+	})
+}
+
+func TestRun_OrderBook(t *testing.T) {
+	r := &routes{results: map[string]string{
+		"/api/markets/BTC-PERP/orderbook": `{"asks":[[47095.5,1],[47096,2]],"bids":[[47091,3],[47090.5,4]]}`,
+	}}
+
+	code, out, _ := runCLI(r, nil, "orderbook", "BTC-PERP", "-depth", "2", "-o", "csv")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "2", string(r.last.URI().QueryArgs().Peek("depth")))
+	assert.Equal(t, "SIDE,PRICE,SIZE\nask,47096,2\nask,47095.5,1\nbid,47091,3\nbid,47090.5,4\n", out)
+}
+
+func TestRun_Candles(t *testing.T) {
+	r := &routes{results: map[string]string{
+		"/api/markets/BTC-PERP/candles": `[{"close":11055.25,"high":11089.0,"low":11043.5,"open":11059.25,"startTime":"2019-06-24T17:15:00+00:00","volume":464193.95725}]`,
+	}}
+
+	code, out, _ := runCLI(r, nil, "candles", "-resolution", "900", "BTC-PERP", "-o", "csv")
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "900", string(r.last.URI().QueryArgs().Peek("resolution")))
+	assert.Equal(t, "START,OPEN,HIGH,LOW,CLOSE,VOLUME\n2019-06-24T17:15:00Z,11059.25,11089,11043.5,11055.25,464193.95725\n", out)
+}
+
+func TestRun_Credentials(t *testing.T) {
+	r := &routes{results: map[string]string{
+		"/api/positions": `[{"future":"BTC-PERP","side":"buy","size":0.5,"netSize":0.5,"entryPrice":47000},{"future":"ETH-PERP","side":"sell","size":2,"netSize":-2}]`,
+	}}
+	env := map[string]string{"FTX_API_KEY": "env-key", "FTX_API_SECRET": "env-secret", "FTX_SUBACCOUNT": "env-sub"}
+
+	t.Run("env", func(t *testing.T) {
+		code, out, _ := runCLI(r, env, "positions", "-future", "ETH-PERP", "-o", "csv")
+
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "env-key", string(r.last.Header.Peek(ftx.HeaderKey)))
+		assert.Equal(t, "env-sub", string(r.last.Header.Peek(ftx.HeaderSubaccount)))
+		assert.NotEmpty(t, r.last.Header.Peek(ftx.HeaderSign))
+		assert.Equal(t, 2, strings.Count(out, "\n"))
+		assert.Contains(t, out, "ETH-PERP,sell,2,-2")
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		code, _, _ := runCLI(r, env, "-key", "flag-key", "-secret", "flag-secret", "positions", "-subaccount", "flag-sub")
+
+		assert.Equal(t, 0, code)
+		assert.Equal(t, "flag-key", string(r.last.Header.Peek(ftx.HeaderKey)))
+		assert.Equal(t, "flag-sub", string(r.last.Header.Peek(ftx.HeaderSubaccount)))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		for _, env := range []map[string]string{nil, {"FTX_API_KEY": "env-key"}} {
+			r.last = nil
+			code, _, errOut := runCLI(r, env, "positions")
+
+			assert.Equal(t, 1, code)
+			assert.Equal(t, "ftx positions: API key and secret not configured\n", errOut)
+			assert.Nil(t, r.last)
+		}
+	})
+}
+
+func TestRun_Leverage(t *testing.T) {
+	r := &routes{results: map[string]string{
+		"/api/account/leverage": `null`,
+		"/api/account":          `{"leverage":10}`,
+	}}
+	env := map[string]string{"FTX_API_KEY": "key", "FTX_API_SECRET": "secret"}
+
+	code, out, errOut := runCLI(r, env, "leverage", "set", "10", "-o", "json")
+
+	assert.Equal(t, 0, code, errOut)
+	assert.JSONEq(t, `{"leverage":10}`, string(r.byPath["/api/account/leverage"].Body()))
+	assert.JSONEq(t, `{"leverage":10}`, out)
+}
+
+func TestRun_Usage(t *testing.T) {
+	r := &routes{}
+
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"market"},
+		{"leverage", "set", "ten"},
+		{"-o", "xml", "markets"},
+	} {
+		code, out, errOut := runCLI(r, nil, args...)
+
+		assert.Equal(t, 2, code, args)
+		assert.Empty(t, out, args)
+		assert.Contains(t, errOut, "Usage: ftx", args)
+	}
+
+	_, _, errOut := runCLI(r, map[string]string{"FTX_API_SECRET": "env-secret"}, "-h")
+	assert.NotContains(t, errOut, "env-secret")
+}
+
+func TestRun_Error(t *testing.T) {
+	code, out, errOut := runCLI(&routes{}, nil, "market", "NOPE")
+
+	assert.Equal(t, 1, code)
+	assert.Empty(t, out)
+	assert.Equal(t, "ftx market: Not found\n", errOut)
+}
@@ -28,6 +28,14 @@ type Account struct {
 	TotalAccountValue            float64 `json:"totalAccountValue"`
 	TotalPositionSize            float64 `json:"totalPositionSize"`
 	Username                     string  `json:"username"`
+	AccountIdentifier            int     `json:"accountIdentifier"`
+	ChargeInterestOnNegativeUSD  bool    `json:"chargeInterestOnNegativeUsd"`
+	FuturesLeverage              float64 `json:"futuresLeverage"`
+	PositionLimit                float64 `json:"positionLimit"`
+	PositionLimitUsed            float64 `json:"positionLimitUsed"`
+	SpotLendingEnabled           bool    `json:"spotLendingEnabled"`
+	SpotMarginEnabled            bool    `json:"spotMarginEnabled"`
+	UseFttCollateral             bool    `json:"useFttCollateral"`
 	Positions                    []Position
 }
 
@@ -56,15 +64,49 @@ type Position struct {
 	Size                         float64 `json:"size"`
 	UnrealizedPnl                float64 `json:"unrealizedPnl"`
 	CollateralUsed               float64 `json:"collateralUsed,omitempty"`
+
+	// Only returned with GetPositionsOptions.ShowAvgPrice.
+	RecentAverageOpenPrice float64 `json:"recentAverageOpenPrice,omitempty"`
+	RecentBreakEvenPrice   float64 `json:"recentBreakEvenPrice,omitempty"`
+	RecentPnl              float64 `json:"recentPnl,omitempty"`
+	CumulativeBuySize      float64 `json:"cumulativeBuySize,omitempty"`
+	CumulativeSellSize     float64 `json:"cumulativeSellSize,omitempty"`
+}
+
+type GetPositionsOptions struct {
+	ShowAvgPrice bool `url:"showAvgPrice,omitempty"`
+	// Future filters the positions by future name. It is applied locally as the endpoint has no such parameter.
+	Future string `url:"-"`
 }
 
 // GetPositions FTX API docs: https://docs.ftx.com/#get-positions
 func (s *AccountService) GetPositions() ([]Position, error) {
+	return s.GetPositionsWithOptions(nil)
+}
+
+// GetPositionsWithOptions is GetPositions with the options of GetPositionsOptions.
+func (s *AccountService) GetPositionsWithOptions(opts *GetPositionsOptions) ([]Position, error) {
 	u := fmt.Sprintf(pathPositions, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
 
 	var out []Position
-	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
-	return out, err
+	if err := s.client.DoPrivate(u, http.MethodGet, nil, &out); err != nil {
+		return out, err
+	}
+	if opts == nil || opts.Future == "" {
+		return out, nil
+	}
+
+	filtered := out[:0]
+	for _, p := range out {
+		if p.Future == opts.Future {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered, nil
 }
 
 const (
@@ -35,6 +35,25 @@ func TestAccountService_GetPositions(t *testing.T) {
 	assert.Equal(t, "ETH-PERP", positions[0].Future)
 }
 
+func TestAccountService_GetPositions_withOptions(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.QueryArgs().QueryString())
+		ctx.SetBodyString(`{"success":true,"result":[{"future":"ETH-PERP"},{"future":"BTC-PERP","recentAverageOpenPrice":57000.5}]}`)
+	}
+
+	positions, err := c.Accounts.GetPositionsWithOptions(&GetPositionsOptions{ShowAvgPrice: true, Future: "BTC-PERP"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "showAvgPrice=true", <-ch)
+	assert.Len(t, positions, 1)
+	assert.Equal(t, 57000.5, positions[0].RecentAverageOpenPrice)
+}
+
 func TestAccountService_SetLeverage(t *testing.T) {
 	c, srv, teardown := setup()
 	defer teardown()
@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Signer signs the payload of an FTX authenticated request and returns the
+// hex encoded signature. Implementations may keep the secret outside of the
+// process, e.g. in an HSM or a separate signing service. payload is reused
+// after Sign returns and must not be retained.
+type Signer interface {
+	Sign(payload []byte) (string, error)
+}
+
+// SignerFunc adapts a function to the Signer interface.
+type SignerFunc func(payload []byte) (string, error)
+
+func (f SignerFunc) Sign(payload []byte) (string, error) {
+	return f(payload)
+}
+
+// HMAC signs with HMAC-SHA256 using an in-memory secret.
+type HMAC struct {
+	secret []byte
+}
+
+func NewHMAC(secret []byte) *HMAC {
+	return &HMAC{secret: secret}
+}
+
+func (h *HMAC) Sign(payload []byte) (string, error) {
+	hash := hmac.New(sha256.New, h.secret)
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
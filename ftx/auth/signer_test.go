@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// example from https://blog.ftx.com/blog/api-authentication/
+func TestHMAC_Sign(t *testing.T) {
+	s := NewHMAC([]byte("T4lPid48QtjNxjLUFOcUZghD7CUJ7sTVsfuvQZF2"))
+
+	sign, err := s.Sign([]byte("1588591511721GET/api/markets"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "dbc62ec300b2624c580611858d94f2332ac636bb86eccfa1167a7777c496ee6f", sign)
+}
+
+func TestSignerFunc_Sign(t *testing.T) {
+	var s Signer = SignerFunc(func(payload []byte) (string, error) {
+		return string(payload), nil
+	})
+
+	sign, err := s.Sign([]byte("payload"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "payload", sign)
+}
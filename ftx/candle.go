@@ -0,0 +1,199 @@
+package ftx
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+)
+
+var resolutions = []int{Resolution1d, Resolution4h, Resolution1h, Resolution15m, Resolution5m, Resolution1m, Resolution15s}
+
+// CandleAggregator builds OHLCV candles of any resolution in seconds from
+// stream.Trade messages. Periods without trades are filled with flat candles at
+// the previous close so the series has no gaps. Volume is in quote currency,
+// as returned by GetHistoricalPrices.
+type CandleAggregator struct {
+	resolution time.Duration
+	current    *Candle
+	traded     bool
+	cutoff     time.Time // trades up to it are included in the seeded candles
+}
+
+// NewCandleAggregator returns an aggregator of candles lasting resolution
+// seconds.
+func NewCandleAggregator(resolution int) (*CandleAggregator, error) {
+	if resolution <= 0 {
+		return nil, fmt.Errorf("invalid candle resolution: %d", resolution)
+	}
+	return &CandleAggregator{resolution: time.Duration(resolution) * time.Second}, nil
+}
+
+// Add applies the trades of msg and returns the candles closed by them.
+func (a *CandleAggregator) Add(msg stream.Trade) []Candle {
+	var closed []Candle
+	for _, t := range msg.Data {
+		closed = append(closed, a.AddTrade(t.Time, t.Price, t.Size)...)
+	}
+	return closed
+}
+
+// AddTrade applies a single trade and returns the candles closed by it. Trades
+// older than the in-progress candle, or already included in the candles of
+// SeedAt or SeedFrom, are ignored.
+func (a *CandleAggregator) AddTrade(t time.Time, price, size float64) []Candle {
+	if !t.After(a.cutoff) {
+		return nil
+	}
+	closed, ok := a.open(a.start(t))
+	if !ok {
+		return nil
+	}
+	a.merge(Candle{Open: price, High: price, Low: price, Close: price, Volume: price * size})
+	return closed
+}
+
+// AddCandle merges a candle of a finer resolution, e.g. from GetHistoricalPrices,
+// and returns the candles closed by it.
+func (a *CandleAggregator) AddCandle(c Candle) []Candle {
+	closed, ok := a.open(a.start(c.StartTime))
+	if !ok {
+		return nil
+	}
+	a.merge(c)
+	return closed
+}
+
+// Seed merges historical candles in start time order and returns the closed
+// candles. If the last candle is still in progress, trades it includes are
+// counted again by AddTrade, see SeedAt.
+func (a *CandleAggregator) Seed(candles []Candle) []Candle {
+	sorted := append([]Candle(nil), candles...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+
+	var closed []Candle
+	for _, c := range sorted {
+		closed = append(closed, a.AddCandle(c)...)
+	}
+	return closed
+}
+
+// SeedAt seeds like Seed candles that include the trades up to asOf, which
+// are then ignored by AddTrade.
+func (a *CandleAggregator) SeedAt(candles []Candle, asOf time.Time) []Candle {
+	if asOf.After(a.cutoff) {
+		a.cutoff = asOf
+	}
+	return a.Seed(candles)
+}
+
+// SeedFrom fetches historical candles of market with the largest FTX resolution
+// that divides the aggregator resolution and seeds them. The resolution of opts
+// is ignored.
+//
+// The last candle includes the trades up to the response, or the end of the
+// candle if it is over. Trades executed while the response is in flight are in
+// neither the candles nor usually the stream, so they are missed, rather than
+// counted twice.
+func (a *CandleAggregator) SeedFrom(s *MarketService, market string, opts *GetHistoricalPrices) ([]Candle, error) {
+	res := 0
+	secs := int(a.resolution / time.Second)
+	for _, r := range resolutions {
+		if secs%r == 0 {
+			res = r
+			break
+		}
+	}
+	if res == 0 {
+		return nil, fmt.Errorf("resolution %v is not a multiple of %ds", a.resolution, Resolution15s)
+	}
+
+	in := GetHistoricalPrices{}
+	if opts != nil {
+		in = *opts
+	}
+	in.Resolution = res
+
+	candles, err := s.GetHistoricalPrices(market, &in)
+	if err != nil {
+		return nil, err
+	}
+
+	// Trades after the end of the last candle are not included, even if the
+	// response is older.
+	asOf := time.Now().Add(s.client.ClockSkew())
+	var end time.Time
+	for _, c := range candles {
+		if e := c.StartTime.Add(time.Duration(res) * time.Second); e.After(end) {
+			end = e
+		}
+	}
+	if end.Before(asOf) {
+		asOf = end
+	}
+	return a.SeedAt(candles, asOf), nil
+}
+
+// Flush closes the candles whose period has ended by now, which is needed when
+// a market has no trades.
+func (a *CandleAggregator) Flush(now time.Time) []Candle {
+	if a.current == nil {
+		return nil
+	}
+	closed, _ := a.open(a.start(now))
+	return closed
+}
+
+// Current returns the in-progress candle.
+func (a *CandleAggregator) Current() (Candle, bool) {
+	if a.current == nil {
+		return Candle{}, false
+	}
+	return *a.current, true
+}
+
+// start returns the start of the candle including t. Candles are aligned to
+// the Unix epoch like those of FTX, which time.Truncate doesn't do for e.g. 3d.
+func (a *CandleAggregator) start(t time.Time) time.Time {
+	n, d := t.UnixNano(), int64(a.resolution)
+	r := n % d
+	if r < 0 {
+		r += d
+	}
+	return time.Unix(0, n-r).In(t.Location())
+}
+
+// open advances the in-progress candle to start, closing the candles before it.
+func (a *CandleAggregator) open(start time.Time) ([]Candle, bool) {
+	if a.current == nil {
+		a.current = &Candle{StartTime: start}
+		a.traded = false
+		return nil, true
+	}
+	if start.Before(a.current.StartTime) {
+		return nil, false
+	}
+
+	var closed []Candle
+	for a.current.StartTime.Before(start) {
+		closed = append(closed, *a.current)
+		c := a.current.Close
+		a.current = &Candle{Open: c, High: c, Low: c, Close: c, StartTime: a.current.StartTime.Add(a.resolution)}
+		a.traded = false
+	}
+	return closed, true
+}
+
+func (a *CandleAggregator) merge(c Candle) {
+	cur := a.current
+	if !a.traded {
+		cur.Open, cur.High, cur.Low = c.Open, c.High, c.Low
+		a.traded = true
+	}
+	cur.High = math.Max(cur.High, c.High)
+	cur.Low = math.Min(cur.Low, c.Low)
+	cur.Close = c.Close
+	cur.Volume += c.Volume
+}
@@ -0,0 +1,161 @@
+package ftx
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCandleAggregator_Add(t *testing.T) {
+	a, _ := NewCandleAggregator(Resolution1m)
+
+	var msg stream.Trade
+	_ = json.Unmarshal([]byte(`{"data":[
+		{"price":10,"size":1,"time":"2021-05-01T00:00:05Z"},
+		{"price":12,"size":1,"time":"2021-05-01T00:00:20Z"},
+		{"price":9,"size":2,"time":"2021-05-01T00:00:50Z"}
+	]}`), &msg)
+
+	assert.Empty(t, a.Add(msg))
+
+	current, ok := a.Current()
+	assert.True(t, ok)
+	assert.Equal(t, Candle{Open: 10, High: 12, Low: 9, Close: 9, Volume: 40, StartTime: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)}, current)
+
+	closed := a.AddTrade(time.Date(2021, 5, 1, 0, 3, 10, 0, time.UTC), 11, 1)
+
+	assert.Len(t, closed, 3)
+	assert.Equal(t, current, closed[0])
+	assert.Equal(t, Candle{Open: 9, High: 9, Low: 9, Close: 9, StartTime: time.Date(2021, 5, 1, 0, 1, 0, 0, time.UTC)}, closed[1])
+	assert.Equal(t, time.Date(2021, 5, 1, 0, 2, 0, 0, time.UTC), closed[2].StartTime)
+
+	current, _ = a.Current()
+	assert.Equal(t, Candle{Open: 11, High: 11, Low: 11, Close: 11, Volume: 11, StartTime: time.Date(2021, 5, 1, 0, 3, 0, 0, time.UTC)}, current)
+
+	assert.Empty(t, a.AddTrade(time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC), 100, 1))
+	assert.Len(t, a.Flush(time.Date(2021, 5, 1, 0, 4, 0, 0, time.UTC)), 1)
+}
+
+func TestCandleAggregator_SeedFrom(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.QueryArgs().Peek("resolution"))
+		ctx.SetBodyString(`{"success":true,"result":[
+			{"open":1,"high":3,"low":1,"close":2,"volume":10,"startTime":"2021-05-01T00:00:00+00:00"},
+			{"open":2,"high":2,"low":0.5,"close":1,"volume":5,"startTime":"2021-05-01T00:01:00+00:00"},
+			{"open":1,"high":4,"low":1,"close":4,"volume":1,"startTime":"2021-05-01T00:02:00+00:00"}
+		]}`)
+	}
+
+	a, _ := NewCandleAggregator(2 * Resolution1m)
+	closed, err := a.SeedFrom(c.Markets, "BTC/USD", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "60", <-ch)
+	assert.Len(t, closed, 1)
+	assert.Equal(t, 3.0, closed[0].High)
+	assert.Equal(t, 0.5, closed[0].Low)
+	assert.Equal(t, 1.0, closed[0].Close)
+	assert.Equal(t, 15.0, closed[0].Volume)
+
+	closed = a.AddTrade(time.Date(2021, 5, 1, 0, 3, 30, 0, time.UTC), 5, 1)
+	assert.Empty(t, closed)
+
+	current, _ := a.Current()
+	assert.True(t, current.StartTime.Equal(time.Date(2021, 5, 1, 0, 2, 0, 0, time.UTC)))
+	assert.Equal(t, 1.0, current.Open)
+	assert.Equal(t, 5.0, current.High)
+	assert.Equal(t, 5.0, current.Close)
+	assert.Equal(t, 6.0, current.Volume)
+
+	a, _ = NewCandleAggregator(7)
+	_, err = a.SeedFrom(c.Markets, "BTC/USD", nil)
+	assert.Error(t, err)
+}
+
+func TestCandleAggregator_SeedFromInProgress(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	start := time.Now().UTC().Truncate(time.Minute)
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[
+			{"open":1,"high":3,"low":1,"close":2,"volume":10,"startTime":"` + start.Format(time.RFC3339) + `"}
+		]}`)
+	}
+
+	a, _ := NewCandleAggregator(Resolution1m)
+	_, err := a.SeedFrom(c.Markets, "BTC/USD", nil)
+	assert.NoError(t, err)
+
+	// The in-progress candle already includes the trades before the response.
+	assert.Empty(t, a.AddTrade(start, 100, 1))
+	current, _ := a.Current()
+	assert.Equal(t, 10.0, current.Volume)
+	assert.Equal(t, 3.0, current.High)
+
+	closed := a.AddTrade(start.Add(time.Minute), 5, 1)
+	assert.Len(t, closed, 1)
+	assert.Equal(t, 10.0, closed[0].Volume)
+}
+
+func TestCandleAggregator_SeedAt(t *testing.T) {
+	start := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	a, _ := NewCandleAggregator(Resolution1m)
+	a.SeedAt([]Candle{{Open: 1, High: 1, Low: 1, Close: 1, Volume: 1, StartTime: start}}, start.Add(30*time.Second))
+
+	a.AddTrade(start.Add(30*time.Second), 2, 1)
+	a.AddTrade(start.Add(40*time.Second), 3, 1)
+
+	current, _ := a.Current()
+	assert.Equal(t, Candle{Open: 1, High: 3, Low: 1, Close: 3, Volume: 4, StartTime: start}, current)
+}
+
+func TestCandleAggregator_EpochAligned(t *testing.T) {
+	at := time.Unix(1600000000, 0).UTC()
+	tests := []struct {
+		resolution int
+		want       time.Time
+	}{
+		{3 * Resolution1d, time.Date(2020, 9, 11, 0, 0, 0, 0, time.UTC)},
+		{7 * Resolution1d, time.Date(2020, 9, 10, 0, 0, 0, 0, time.UTC)},
+		{7, time.Unix(1600000000-1600000000%7, 0).UTC()},
+	}
+	for _, tt := range tests {
+		a, err := NewCandleAggregator(tt.resolution)
+		assert.NoError(t, err)
+
+		a.AddTrade(at, 1, 1)
+		current, _ := a.Current()
+		assert.Equal(t, tt.want, current.StartTime, "resolution %d", tt.resolution)
+	}
+
+	// Daily candles are added to the 3d candle they belong to.
+	a, _ := NewCandleAggregator(3 * Resolution1d)
+	closed := a.Seed([]Candle{
+		{Close: 1, Volume: 1, StartTime: time.Date(2020, 9, 10, 0, 0, 0, 0, time.UTC)},
+		{Close: 2, Volume: 1, StartTime: time.Date(2020, 9, 11, 0, 0, 0, 0, time.UTC)},
+		{Close: 3, Volume: 1, StartTime: time.Date(2020, 9, 12, 0, 0, 0, 0, time.UTC)},
+	})
+	assert.Len(t, closed, 1)
+	assert.Equal(t, time.Date(2020, 9, 8, 0, 0, 0, 0, time.UTC), closed[0].StartTime)
+	current, _ := a.Current()
+	assert.Equal(t, 2.0, current.Volume)
+}
+
+func TestNewCandleAggregator_InvalidResolution(t *testing.T) {
+	a, err := NewCandleAggregator(0)
+	assert.Nil(t, a)
+	assert.EqualError(t, err, "invalid candle resolution: 0")
+
+	_, err = NewCandleAggregator(-60)
+	assert.Error(t, err)
+}
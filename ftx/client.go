@@ -2,15 +2,16 @@ package ftx
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/cloudingcity/go-ftx/ftx/auth"
 	"github.com/cloudingcity/go-ftx/ftx/stream"
 	"github.com/gorilla/websocket"
 	"github.com/valyala/fasthttp"
@@ -34,16 +35,32 @@ type service struct {
 
 type Client struct {
 	baseURL string
-	client  *fasthttp.Client
+	client  Transport
+
+	wsURL         string
+	wsCompression bool
 
 	key        string
-	secret     []byte
+	signer     auth.Signer
 	subaccount string
 
+	clock     *clock
+	clockSync bool
+
+	paper      *PaperExchange
+	middleware []Middleware
+
 	common service // Reuse a single struct instead of allocating one for each service on the heap.
 
-	Accounts *AccountService
-	Markets  *MarketService
+	Accounts        *AccountService
+	Convert         *ConvertService
+	LeveragedTokens *LeveragedTokenService
+	Markets         *MarketService
+	NFTs            *NFTService
+	Options         *OptionsService
+	Orders          *OrderService
+	SpotMargin      *SpotMarginService
+	Staking         *StakingService
 }
 
 func New(opts ...Option) *Client {
@@ -53,10 +70,8 @@ func New(opts ...Option) *Client {
 		WriteTimeout: 6 * time.Second,
 	}
 
-	c := &Client{baseURL: defaultBaseURL, client: httpClient}
-	c.common.client = c
-	c.Accounts = (*AccountService)(&c.common)
-	c.Markets = (*MarketService)(&c.common)
+	c := &Client{baseURL: defaultBaseURL, client: httpClient, wsURL: defaultBaseWSURL, clock: &clock{}}
+	c.init()
 
 	for _, opt := range opts {
 		opt(c)
@@ -65,6 +80,28 @@ func New(opts ...Option) *Client {
 	return c
 }
 
+func (c *Client) init() {
+	c.common.client = c
+	c.Accounts = (*AccountService)(&c.common)
+	c.Convert = (*ConvertService)(&c.common)
+	c.LeveragedTokens = (*LeveragedTokenService)(&c.common)
+	c.Markets = (*MarketService)(&c.common)
+	c.NFTs = (*NFTService)(&c.common)
+	c.Options = (*OptionsService)(&c.common)
+	c.Orders = (*OrderService)(&c.common)
+	c.SpotMargin = (*SpotMarginService)(&c.common)
+	c.Staking = (*StakingService)(&c.common)
+}
+
+// ForSubaccount returns a client acting on behalf of the subaccount name, or
+// the main account if name is empty. It shares the HTTP client and settings of c.
+func (c *Client) ForSubaccount(name string) *Client {
+	sub := *c
+	sub.subaccount = name
+	sub.init()
+	return &sub
+}
+
 func (c *Client) DoPublic(uri string, method string, in, out interface{}) error {
 	return c.do(uri, method, in, out, false)
 }
@@ -96,39 +133,51 @@ func (c *Client) do(uri string, method string, in, out interface{}, isPrivate bo
 		}
 	}
 
-	if isPrivate {
+	send := DoFunc(c.client.Do)
+	switch {
+	case isPrivate && c.paper != nil:
+		// Paper requests are answered locally, after the middleware, and need no signature.
+		send = func(req *fasthttp.Request, resp *fasthttp.Response) error {
+			return c.paper.serve(strings.TrimPrefix(uri, c.baseURL), req, resp)
+		}
+	case isPrivate:
 		if err := c.auth(req); err != nil {
 			return err
 		}
 	}
 
-	if err := c.client.Do(req, resp); err != nil {
-		return err
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		send = c.middleware[i](send)
 	}
 
-	var data Response
-	if out != nil {
-		data.Result = out
-	}
-	if err := json.Unmarshal(resp.Body(), &data); err != nil {
-		return fmt.Errorf("unmarshal: [%v] body: %v, error: %v", resp.StatusCode(), string(resp.Body()), err)
+	sent := time.Now()
+	if err := send(req, resp); err != nil {
+		return err
 	}
-	if !data.Success {
-		return errors.New(data.Error)
+	if c.clockSync {
+		if date, err := http.ParseTime(string(resp.Header.Peek(fasthttp.HeaderDate))); err == nil {
+			c.clock.observe(sent, time.Now(), date, time.Second)
+		}
 	}
 
-	return nil
+	return decodeResponse(resp.StatusCode(), resp.Body(), out)
 }
 
+// payloadPool holds the buffers of the payloads signed by auth. Request bodies
+// are encoded into the pooled buffers of fasthttp.
+var payloadPool = sync.Pool{New: func() interface{} { return new(bytes.Buffer) }}
+
 // FTX API Authentication docs: https://blog.ftx.com/blog/api-authentication/
 func (c *Client) auth(req *fasthttp.Request) error {
-	if c.key == "" || len(c.secret) == 0 {
+	if c.key == "" || c.signer == nil {
 		return errors.New("API key and secret not configured")
 	}
 
-	var payload bytes.Buffer
+	payload := payloadPool.Get().(*bytes.Buffer)
+	payload.Reset()
+	defer payloadPool.Put(payload)
 
-	ts := strconv.FormatInt(unixTime(), 10)
+	ts := strconv.FormatInt(c.clock.now(), 10)
 
 	payload.WriteString(ts)
 	payload.Write(req.Header.Method())
@@ -137,22 +186,32 @@ func (c *Client) auth(req *fasthttp.Request) error {
 		payload.Write(req.Body())
 	}
 
-	hash := hmac.New(sha256.New, c.secret)
-	hash.Write(payload.Bytes())
+	sign, err := c.signer.Sign(payload.Bytes())
+	if err != nil {
+		return err
+	}
 
 	req.Header.Set(HeaderKey, c.key)
-	req.Header.Set(HeaderSign, hex.EncodeToString(hash.Sum(nil)))
+	req.Header.Set(HeaderSign, sign)
 	req.Header.Set(HeaderTS, ts)
 	if c.subaccount != "" {
-		req.Header.Set(HeaderSubaccount, c.subaccount)
+		req.Header.Set(HeaderSubaccount, url.QueryEscape(c.subaccount))
 	}
 	return nil
 }
 
-func (c *Client) Connect() (*stream.Conn, error) {
-	conn, _, err := websocket.DefaultDialer.Dial(defaultBaseWSURL, nil)
+// Connect opens a websocket connection. opts are applied after the signer and
+// clock offset of c, e.g. stream.WithWatchdog.
+func (c *Client) Connect(opts ...stream.Option) (*stream.Conn, error) {
+	dialer := *websocket.DefaultDialer
+	dialer.EnableCompression = c.wsCompression
+
+	conn, _, err := dialer.Dial(c.wsURL, nil)
 	if err != nil {
 		return nil, err
 	}
-	return stream.New(conn, c.key, c.secret, c.subaccount), nil
+	// Only received messages are compressed, see stream.WithWriteCompression.
+	conn.EnableWriteCompression(false)
+	opts = append([]stream.Option{stream.WithSigner(c.signer), stream.WithClockOffset(c.ClockSkew)}, opts...)
+	return stream.New(conn, c.key, nil, c.subaccount, opts...), nil
 }
@@ -2,9 +2,12 @@ package ftx
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fasthttp"
 )
@@ -69,6 +72,31 @@ func TestClient_Do(t *testing.T) {
 	})
 }
 
+func TestClient_ForSubaccount(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Header.Peek(HeaderSubaccount))
+		ctx.SetBodyString(`{"success":true,"result":{"username":"john@example.com"}}`)
+	}
+
+	sub := c.ForSubaccount("my/account")
+
+	_, err := sub.Accounts.GetInformation()
+	assert.NoError(t, err)
+	assert.Equal(t, "my%2Faccount", <-ch)
+
+	_, err = c.Accounts.GetInformation()
+	assert.NoError(t, err)
+	assert.Equal(t, "", <-ch)
+
+	assert.Same(t, c.client, sub.client)
+	assert.Same(t, sub, sub.Accounts.client)
+}
+
 // example from https://blog.ftx.com/blog/api-authentication/
 func TestClient_auth(t *testing.T) {
 	const (
@@ -118,3 +146,93 @@ func TestClient_auth(t *testing.T) {
 		assert.EqualValues(t, subaccount, req.Header.Peek(HeaderSubaccount))
 	})
 }
+
+func TestClient_MissingCredentials(t *testing.T) {
+	for name, opt := range map[string]Option{
+		"no key":    WithAuth("", "api-secret"),
+		"no secret": WithAuth("api-key", ""),
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, srv, teardown := setup()
+			defer teardown()
+			opt(c)
+
+			var sent bool
+			srv.Handler = func(ctx *fasthttp.RequestCtx) {
+				sent = true
+				ctx.SetBodyString(`{"success":true,"result":{}}`)
+			}
+
+			_, err := c.Accounts.GetInformation()
+
+			assert.EqualError(t, err, "API key and secret not configured")
+			assert.False(t, sent)
+		})
+	}
+}
+
+func TestClient_Connect(t *testing.T) {
+	extensions := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		extensions <- r.Header.Get("Sec-Websocket-Extensions")
+		ws, err := (&websocket.Upgrader{EnableCompression: true}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		_ = ws.WriteMessage(websocket.TextMessage, []byte(`{"type": "pong"}`))
+		_, _, _ = ws.ReadMessage()
+	}))
+	defer srv.Close()
+
+	connect := func(opts ...Option) string {
+		c := New(opts...)
+		c.wsURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+
+		conn, err := c.Connect()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		msg, err := conn.RecvRaw()
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"type": "pong"}`, string(msg))
+		return <-extensions
+	}
+
+	t.Run("default", func(t *testing.T) {
+		assert.Empty(t, connect())
+	})
+
+	t.Run("compression", func(t *testing.T) {
+		assert.Contains(t, connect(WithCompression(true)), "permessage-deflate")
+	})
+}
+
+func TestClient_ConnectSubaccount(t *testing.T) {
+	logins := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		var req struct {
+			Args struct {
+				Subaccount string `json:"subaccount"`
+			} `json:"args"`
+		}
+		_ = ws.ReadJSON(&req)
+		logins <- req.Args.Subaccount
+	}))
+	defer srv.Close()
+
+	c := New(WithAuth("key", "secret")).ForSubaccount("my sub")
+	c.wsURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	conn, err := c.Connect()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	assert.NoError(t, conn.Login())
+	assert.Equal(t, "my sub", <-logins)
+}
@@ -0,0 +1,65 @@
+package ftx
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const pathTime = "%s/time"
+
+// clock tracks the offset of the exchange clock from the local clock.
+type clock struct {
+	offset int64 // nanoseconds, accessed atomically
+}
+
+func (k *clock) Offset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&k.offset))
+}
+
+func (k *clock) set(d time.Duration) {
+	atomic.StoreInt64(&k.offset, int64(d))
+}
+
+// bounds returns the range of offsets consistent with a server time in
+// [server, server+precision) observed between sent and recv.
+func bounds(sent, recv, server time.Time, precision time.Duration) (lo, hi time.Duration) {
+	return server.Sub(recv), server.Add(precision).Sub(sent)
+}
+
+// observe adjusts the offset when it is inconsistent with the observation.
+func (k *clock) observe(sent, recv, server time.Time, precision time.Duration) {
+	lo, hi := bounds(sent, recv, server, precision)
+	if cur := k.Offset(); cur >= lo && cur <= hi {
+		return
+	}
+	k.set((lo + hi) / 2)
+}
+
+// now returns the estimated exchange time in milliseconds.
+func (k *clock) now() int64 {
+	return unixTime() + k.Offset().Milliseconds()
+}
+
+// ClockSkew returns the measured offset of the exchange clock from the local
+// clock, which is added to the timestamps of signed requests.
+func (c *Client) ClockSkew() time.Duration {
+	return c.clock.Offset()
+}
+
+// SyncTime measures the clock offset against the exchange time endpoint.
+func (c *Client) SyncTime() (time.Duration, error) {
+	u := fmt.Sprintf(pathTime, c.baseURL)
+
+	var server time.Time
+	sent := time.Now()
+	if err := c.DoPublic(u, http.MethodGet, nil, &server); err != nil {
+		return c.clock.Offset(), err
+	}
+	recv := time.Now()
+
+	lo, hi := bounds(sent, recv, server, time.Millisecond)
+	c.clock.set((lo + hi) / 2)
+	return c.clock.Offset(), nil
+}
@@ -0,0 +1,60 @@
+package ftx
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestClient_SyncTime(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		server := time.Now().Add(time.Hour).UTC().Format(time.RFC3339Nano)
+		ctx.SetBodyString(`{"success":true,"result":"` + server + `"}`)
+	}
+
+	skew, err := c.SyncTime()
+
+	assert.NoError(t, err)
+	assert.InDelta(t, float64(time.Hour), float64(skew), float64(100*time.Millisecond))
+	assert.Equal(t, skew, c.ClockSkew())
+}
+
+func TestClock_observe(t *testing.T) {
+	var k clock
+	sent := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	recv := sent.Add(200 * time.Millisecond)
+
+	k.observe(sent, recv, sent.Add(-time.Minute), time.Second)
+	assert.Equal(t, -(time.Minute - 400*time.Millisecond), k.Offset())
+
+	k.observe(sent, recv, sent.Add(-time.Minute+300*time.Millisecond), time.Second)
+	assert.Equal(t, -(time.Minute - 400*time.Millisecond), k.Offset(), "offset consistent with the observation is kept")
+
+	k.observe(sent, recv, sent, time.Second)
+	assert.Equal(t, 400*time.Millisecond, k.Offset())
+}
+
+func TestClient_auth_clockSkew(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Header.Peek(HeaderTS))
+		ctx.SetBodyString(`{"success":true,"result":null}`)
+	}
+
+	unixTime = func() int64 { return 1588591511721 }
+	c.clock.set(-time.Minute)
+
+	assert.NoError(t, c.DoPrivate("http://example.com/", http.MethodGet, nil, nil))
+	assert.Equal(t, strconv.Itoa(1588591511721-60000), <-ch)
+}
@@ -0,0 +1,140 @@
+package ftx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+)
+
+type ConvertService service
+
+const (
+	pathConvertQuotes = "%s/otc/quotes"
+	pathConvertQuote  = "%s/otc/quotes/%d"
+	pathConvertAccept = "%s/otc/quotes/%d/accept"
+)
+
+var (
+	ErrQuoteExpired          = errors.New("quote expired")
+	ErrQuoteOutsideTolerance = errors.New("quote outside price tolerance")
+)
+
+type RequestQuotePayload struct {
+	FromCoin string  `json:"fromCoin"`
+	ToCoin   string  `json:"toCoin"`
+	Size     float64 `json:"size"`
+}
+
+type QuoteID struct {
+	QuoteID int `json:"quoteId"`
+}
+
+// RequestQuote FTX API docs: https://docs.ftx.com/#request-quote
+func (s *ConvertService) RequestQuote(in *RequestQuotePayload) (int, error) {
+	u := fmt.Sprintf(pathConvertQuotes, s.client.baseURL)
+
+	var out QuoteID
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return out.QuoteID, err
+}
+
+type Quote struct {
+	ID        int          `json:"id"`
+	BaseCoin  string       `json:"baseCoin"`
+	QuoteCoin string       `json:"quoteCoin"`
+	FromCoin  string       `json:"fromCoin"`
+	ToCoin    string       `json:"toCoin"`
+	Side      string       `json:"side"`
+	Price     float64      `json:"price"`
+	Cost      float64      `json:"cost"`
+	Proceeds  float64      `json:"proceeds"`
+	Expired   bool         `json:"expired"`
+	Expiry    *stream.Time `json:"expiry"`
+	Filled    bool         `json:"filled"`
+}
+
+// Rate returns the amount of ToCoin received per FromCoin spent.
+func (q *Quote) Rate() float64 {
+	if q.Cost == 0 {
+		return 0
+	}
+	return q.Proceeds / q.Cost
+}
+
+// GetQuote FTX API docs: https://docs.ftx.com/#get-quote-status
+func (s *ConvertService) GetQuote(id int) (*Quote, error) {
+	u := fmt.Sprintf(pathConvertQuote, s.client.baseURL, id)
+
+	var out Quote
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return &out, err
+}
+
+// AcceptQuote FTX API docs: https://docs.ftx.com/#accept-quote
+func (s *ConvertService) AcceptQuote(id int) error {
+	u := fmt.Sprintf(pathConvertAccept, s.client.baseURL, id)
+	return s.client.DoPrivate(u, http.MethodPost, nil, nil)
+}
+
+const (
+	defaultConvertPollInterval = 200 * time.Millisecond
+	defaultConvertTimeout      = 10 * time.Second
+)
+
+type ConvertOptions struct {
+	// Rate is the expected amount of ToCoin per FromCoin.
+	Rate float64
+	// Tolerance is the accepted relative shortfall of the quoted rate, e.g. 0.005 for 0.5%.
+	Tolerance    float64
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// Convert requests a quote, polls it until it is priced and accepts it if the
+// quoted rate is no worse than opts.Rate by more than opts.Tolerance. The quote
+// is returned with ErrQuoteOutsideTolerance when it is not accepted.
+func (s *ConvertService) Convert(in *RequestQuotePayload, opts *ConvertOptions) (*Quote, error) {
+	if opts == nil || opts.Rate <= 0 {
+		return nil, errors.New("convert: expected rate not configured")
+	}
+	interval, timeout := opts.PollInterval, opts.Timeout
+	if interval <= 0 {
+		interval = defaultConvertPollInterval
+	}
+	if timeout <= 0 {
+		timeout = defaultConvertTimeout
+	}
+
+	id, err := s.RequestQuote(in)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		q, err := s.GetQuote(id)
+		if err != nil {
+			return nil, err
+		}
+		if q.Expired {
+			return q, ErrQuoteExpired
+		}
+		if q.Cost > 0 && q.Proceeds > 0 {
+			if q.Rate() < opts.Rate*(1-opts.Tolerance) {
+				return q, ErrQuoteOutsideTolerance
+			}
+			if err := s.AcceptQuote(id); err != nil {
+				return q, err
+			}
+			q.Filled = true
+			return q, nil
+		}
+		if time.Now().After(deadline) {
+			return q, fmt.Errorf("convert: quote %d not priced within %v", id, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,104 @@
+package ftx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestConvertService_RequestQuote(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"quoteId":9999}}`)
+	}
+
+	id, err := c.Convert.RequestQuote(&RequestQuotePayload{FromCoin: "EUR", ToCoin: "BTC", Size: 100})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"fromCoin":"EUR","toCoin":"BTC","size":100}`, <-ch)
+	assert.Equal(t, 9999, id)
+}
+
+func TestConvertService_GetQuote(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":{"baseCoin":"BTC","cost":100,"expired":false,"expiry":1593106567.0,"filled":false,"fromCoin":"EUR","id":9999,"price":7500.0,"proceeds":0.01333,"quoteCoin":"EUR","side":"buy","toCoin":"BTC"}}`)
+	}
+
+	quote, err := c.Convert.GetQuote(9999)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 9999, quote.ID)
+	assert.Equal(t, int64(1593106567), quote.Expiry.Unix())
+	assert.Equal(t, 0.0001333, quote.Rate())
+}
+
+func TestConvertService_AcceptQuote(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":null}`)
+	}
+
+	err := c.Convert.AcceptQuote(9999)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/otc/quotes/9999/accept", <-ch)
+}
+
+func TestConvertService_Convert(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	var polls int
+	accepted := make(chan bool, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		switch string(ctx.Request.URI().Path()) {
+		case "/otc/quotes":
+			polls = 0
+			ctx.SetBodyString(`{"success":true,"result":{"quoteId":1}}`)
+		case "/otc/quotes/1":
+			if polls++; polls == 1 {
+				ctx.SetBodyString(`{"success":true,"result":{"id":1,"expired":false}}`)
+				return
+			}
+			ctx.SetBodyString(`{"success":true,"result":{"id":1,"cost":100,"proceeds":0.0099}}`)
+		case "/otc/quotes/1/accept":
+			accepted <- true
+			ctx.SetBodyString(`{"success":true,"result":null}`)
+		}
+	}
+
+	in := &RequestQuotePayload{FromCoin: "USD", ToCoin: "BTC", Size: 100}
+
+	t.Run("accept", func(t *testing.T) {
+		quote, err := c.Convert.Convert(in, &ConvertOptions{Rate: 0.0001, Tolerance: 0.02, PollInterval: time.Millisecond})
+
+		assert.NoError(t, err)
+		assert.True(t, <-accepted)
+		assert.True(t, quote.Filled)
+		assert.Equal(t, 2, polls)
+	})
+
+	t.Run("outside tolerance", func(t *testing.T) {
+		quote, err := c.Convert.Convert(in, &ConvertOptions{Rate: 0.0001, Tolerance: 0.005, PollInterval: time.Millisecond})
+
+		assert.Equal(t, ErrQuoteOutsideTolerance, err)
+		assert.False(t, quote.Filled)
+		assert.Empty(t, accepted)
+	})
+}
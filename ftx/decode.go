@@ -0,0 +1,283 @@
+package ftx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/cloudingcity/go-ftx/ftx/internal/jsonscan"
+)
+
+var (
+	// errDecoded stops scanning a response whose result has been decoded.
+	errDecoded = errors.New("decoded")
+	// errSlowPath gives up scanning a response that encoding/json must decode.
+	errSlowPath = errors.New("slow path")
+)
+
+// decodeResponse decodes the result of body into out. The envelope is scanned
+// in place so that out is decoded directly instead of through Response.Result.
+//
+// Markets, trades and candles, the largest lists, are parsed by hand while
+// scanning. Other results are decoded by encoding/json, first as the rest of
+// the body since FTX puts the result last. If more keys follow, the result is
+// scanned and decoded on its own.
+func decodeResponse(status int, body []byte, out interface{}) error {
+	var success, decoded bool
+	var msg, result []byte
+	var decodeErr error
+
+	s := jsonscan.New(body)
+	err := s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "success":
+			success, err = s.Bool()
+		case "error":
+			msg, err = s.Str()
+		case "result":
+			s.WS()
+			start := s.Pos()
+			if success && out != nil {
+				if ok, err := decodeResult(s, out); ok {
+					if err != nil {
+						return errSlowPath
+					}
+					decoded = true
+					return nil
+				}
+				if rest, ok := trimObjectEnd(body[start:]); ok {
+					decodeErr = json.Unmarshal(rest, out)
+					if _, syntax := decodeErr.(*json.SyntaxError); !syntax {
+						return errDecoded
+					}
+					decodeErr = nil
+				}
+			}
+			err = s.Skip()
+			result = body[start:s.Pos()]
+		default:
+			err = s.Skip()
+		}
+		return err
+	})
+	switch err {
+	case errDecoded:
+		if decodeErr != nil {
+			return fmt.Errorf("unmarshal: [%v] body: %v, error: %v", status, string(body), decodeErr)
+		}
+		return nil
+	case errSlowPath:
+		return decodeResponseSlow(status, body, out)
+	}
+	if s.WS(); err == nil && s.Pos() != len(body) {
+		err = errors.New("invalid character after top-level value")
+	}
+	if err != nil {
+		// Report the error of encoding/json, which is what callers have been seeing.
+		var data Response
+		if jsonErr := json.Unmarshal(body, &data); jsonErr != nil {
+			err = jsonErr
+		}
+		return fmt.Errorf("unmarshal: [%v] body: %v, error: %v", status, string(body), err)
+	}
+	if !success {
+		return errors.New(string(msg))
+	}
+
+	if out != nil && result != nil && !decoded {
+		if ok, err := decodeResult(jsonscan.New(result), out); ok && err == nil {
+			return nil
+		}
+		if err := json.Unmarshal(result, out); err != nil {
+			return fmt.Errorf("unmarshal: [%v] body: %v, error: %v", status, string(body), err)
+		}
+	}
+	return nil
+}
+
+// decodeResponseSlow decodes body with encoding/json only. It is used when a
+// result could not be parsed by hand, so that errors stay those of encoding/json.
+func decodeResponseSlow(status int, body []byte, out interface{}) error {
+	var data Response
+	if out != nil {
+		data.Result = out
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("unmarshal: [%v] body: %v, error: %v", status, string(body), err)
+	}
+	if !data.Success {
+		return errors.New(data.Error)
+	}
+	return nil
+}
+
+// trimObjectEnd strips the closing brace, and white space around it, from the end of b.
+func trimObjectEnd(b []byte) ([]byte, bool) {
+	b = bytes.TrimRight(b, " \t\r\n")
+	if len(b) == 0 || b[len(b)-1] != '}' {
+		return nil, false
+	}
+	return b[:len(b)-1], true
+}
+
+// decodeResult parses the result at s into out if it has a hand-written
+// decoder, and reports whether it has. out is only set on success.
+func decodeResult(s *jsonscan.Scanner, out interface{}) (bool, error) {
+	switch v := out.(type) {
+	case *[]Market:
+		return true, decodeMarkets(s, v)
+	case *[]Trade:
+		return true, decodeTrades(s, v)
+	case *[]Candle:
+		return true, decodeCandles(s, v)
+	}
+	return false, nil
+}
+
+func decodeMarkets(s *jsonscan.Scanner, out *[]Market) error {
+	if s.Null() {
+		*out = nil
+		return nil
+	}
+	list := []Market{}
+	err := s.Array(func() error {
+		list = append(list, Market{})
+		m := &list[len(list)-1]
+		return s.Object(func(key []byte) error {
+			var b []byte
+			var err error
+			switch string(key) {
+			case "name":
+				b, err = s.Str()
+				m.Name = string(b)
+			case "baseCurrency":
+				b, err = s.Str()
+				m.BaseCurrency = string(b)
+			case "quoteCurrency":
+				b, err = s.Str()
+				m.QuoteCurrency = string(b)
+			case "type":
+				b, err = s.Str()
+				m.Type = intern(b)
+			case "underlying":
+				b, err = s.Str()
+				m.Underlying = string(b)
+			case "enabled":
+				m.Enabled, err = s.Bool()
+			case "ask":
+				m.Ask, err = s.Float()
+			case "bid":
+				m.Bid, err = s.Float()
+			case "last":
+				m.Last, err = s.Float()
+			case "postOnly":
+				m.PostOnly, err = s.Bool()
+			case "priceIncrement":
+				m.PriceIncrement, err = s.Float()
+			case "sizeIncrement":
+				m.SizeIncrement, err = s.Float()
+			case "restricted":
+				m.Restricted, err = s.Bool()
+			default:
+				err = s.Skip()
+			}
+			return err
+		})
+	})
+	if err == nil {
+		*out = list
+	}
+	return err
+}
+
+func decodeTrades(s *jsonscan.Scanner, out *[]Trade) error {
+	if s.Null() {
+		*out = nil
+		return nil
+	}
+	list := []Trade{}
+	err := s.Array(func() error {
+		list = append(list, Trade{})
+		t := &list[len(list)-1]
+		return s.Object(func(key []byte) error {
+			var err error
+			switch string(key) {
+			case "id":
+				t.ID, err = s.Int()
+			case "liquidation":
+				t.Liquidation, err = s.Bool()
+			case "price":
+				t.Price, err = s.Float()
+			case "side":
+				var b []byte
+				b, err = s.Str()
+				t.Side = intern(b)
+			case "size":
+				t.Size, err = s.Float()
+			case "time":
+				t.Time, err = s.Time()
+			default:
+				err = s.Skip()
+			}
+			return err
+		})
+	})
+	if err == nil {
+		*out = list
+	}
+	return err
+}
+
+func decodeCandles(s *jsonscan.Scanner, out *[]Candle) error {
+	if s.Null() {
+		*out = nil
+		return nil
+	}
+	list := []Candle{}
+	err := s.Array(func() error {
+		list = append(list, Candle{})
+		c := &list[len(list)-1]
+		return s.Object(func(key []byte) error {
+			var err error
+			switch string(key) {
+			case "close":
+				c.Close, err = s.Float()
+			case "high":
+				c.High, err = s.Float()
+			case "low":
+				c.Low, err = s.Float()
+			case "open":
+				c.Open, err = s.Float()
+			case "startTime":
+				c.StartTime, err = s.Time()
+			case "volume":
+				c.Volume, err = s.Float()
+			default:
+				err = s.Skip()
+			}
+			return err
+		})
+	})
+	if err == nil {
+		*out = list
+	}
+	return err
+}
+
+// intern returns b as a string without allocating for values repeated in
+// every element, like market types and sides.
+func intern(b []byte) string {
+	switch string(b) {
+	case "spot":
+		return "spot"
+	case "future":
+		return "future"
+	case "buy":
+		return "buy"
+	case "sell":
+		return "sell"
+	}
+	return string(b)
+}
@@ -0,0 +1,217 @@
+package ftx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/internal/jsonscan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	t.Run("result", func(t *testing.T) {
+		var out []Market
+		err := decodeResponse(http.StatusOK, []byte(`{"result": [{"name": "BTC-PERP", "extra": {"a": [1, null]}}], "success": true}`), &out)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "BTC-PERP", out[0].Name)
+	})
+
+	t.Run("no output", func(t *testing.T) {
+		err := decodeResponse(http.StatusOK, []byte(`{"success":true,"result":{"foo":"bar"}}`), nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		var out []Market
+		err := decodeResponse(http.StatusBadRequest, []byte(`{"success":false,"error":"Not \"allowed\"","result":null}`), &out)
+
+		assert.EqualError(t, err, `Not "allowed"`)
+		assert.Nil(t, out)
+	})
+
+	t.Run("wrong result", func(t *testing.T) {
+		var out []Market
+		err := decodeResponse(http.StatusOK, []byte(`{"success":true,"result":{"name":"BTC-PERP"}}`), &out)
+
+		assert.EqualError(t, err, `unmarshal: [200] body: {"success":true,"result":{"name":"BTC-PERP"}}, error: json: cannot unmarshal object into Go struct field Response.result of type []ftx.Market`)
+	})
+
+	t.Run("trailing data", func(t *testing.T) {
+		err := decodeResponse(http.StatusOK, []byte(`{"success":true} x`), nil)
+		assert.Error(t, err)
+	})
+	t.Run("success after result", func(t *testing.T) {
+		var out []Candle
+		err := decodeResponse(http.StatusOK, []byte(`{"result":[{"close":1.5}],"success":true}`), &out)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []Candle{{Close: 1.5}}, out)
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		var out []Trade
+		err := decodeResponse(http.StatusOK, []byte(`{"success":true,"result":[{"id":"1"}]}`), &out)
+
+		assert.EqualError(t, err, `unmarshal: [200] body: {"success":true,"result":[{"id":"1"}]}, error: json: cannot unmarshal string into Go struct field Response.result.0.id of type int`)
+	})
+}
+
+func TestDecodeResult(t *testing.T) {
+	for name, tt := range map[string]struct {
+		result    string
+		out, want interface{}
+	}{
+		"markets": {
+			result: `[{"name":"BTC-0628","baseCurrency":null,"quoteCurrency":"USD","type":"future","underlying":"BTC","enabled":true,"ask":3949.25,"bid":3949,"last":10579.52,"postOnly":false,"priceIncrement":0.25,"sizeIncrement":0.001,"restricted":true,"extra":[{}]}]`,
+			out:    &[]Market{},
+			want:   &[]Market{},
+		},
+		"trades": {
+			result: `[{"id":3855995,"liquidation":true,"price":3857.75,"side":"buy","size":0.111,"time":"2019-03-20T18:16:23.397991+00:00"}]`,
+			out:    &[]Trade{},
+			want:   &[]Trade{},
+		},
+		"candles": {
+			result: `[{"close":11055.25,"high":11089.0,"low":11043.5,"open":11059.25,"startTime":"2019-06-24T17:15:00+00:00","volume":464193.95725}]`,
+			out:    &[]Candle{},
+			want:   &[]Candle{},
+		},
+		"empty": {
+			result: ` [ ] `,
+			out:    &[]Candle{},
+			want:   &[]Candle{},
+		},
+		"null": {
+			result: `null`,
+			out:    &[]Candle{{Close: 1}},
+			want:   &[]Candle{{Close: 1}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ok, err := decodeResult(jsonscan.New([]byte(tt.result)), tt.out)
+			assert.True(t, ok)
+			assert.NoError(t, err)
+
+			assert.NoError(t, json.Unmarshal([]byte(tt.result), tt.want))
+			// Times are in UTC rather than a fixed +00:00 zone.
+			assert.Equal(t, fmt.Sprintf("%+v", tt.want), fmt.Sprintf("%+v", tt.out))
+		})
+	}
+
+	ok, _ := decodeResult(jsonscan.New([]byte(`{}`)), &Account{})
+	assert.False(t, ok)
+}
+
+// TestDecodeResult_Fields fails when a field of the hand-decoded types is
+// missing from its decoder, or decoded from another key than its JSON tag.
+func TestDecodeResult_Fields(t *testing.T) {
+	for name, out := range map[string]interface{}{
+		"markets": &[]Market{},
+		"trades":  &[]Trade{},
+		"candles": &[]Candle{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			list := reflect.New(reflect.TypeOf(out).Elem()).Elem()
+			list.Set(reflect.Append(list, reflect.New(list.Type().Elem()).Elem()))
+			fill(t, list.Index(0))
+			result, err := json.Marshal(list.Interface())
+			assert.NoError(t, err)
+
+			ok, err := decodeResult(jsonscan.New(result), out)
+			assert.True(t, ok)
+			assert.NoError(t, err)
+
+			want := reflect.New(list.Type())
+			assert.NoError(t, json.Unmarshal(result, want.Interface()))
+			assert.Equal(t, want.Interface(), out)
+		})
+	}
+}
+
+// fill sets every field of the struct v to a distinct non-zero value.
+func fill(t *testing.T, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Interface().(type) {
+		case string:
+			f.SetString(v.Type().Field(i).Name)
+		case bool:
+			f.SetBool(true)
+		case int:
+			f.SetInt(int64(i + 1))
+		case float64:
+			f.SetFloat(float64(i) + 0.5)
+		case time.Time:
+			f.Set(reflect.ValueOf(time.Date(2021, 9, 1, 0, 0, i, 0, time.UTC)))
+		default:
+			t.Fatalf("fill: unsupported field %s", v.Type().Field(i).Name)
+		}
+	}
+}
+
+// decodeResponseReflect is how responses were decoded before decodeResponse.
+func decodeResponseReflect(body []byte, out interface{}) error {
+	var data Response
+	if out != nil {
+		data.Result = out
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return err
+	}
+	if !data.Success {
+		return errors.New(data.Error)
+	}
+	return nil
+}
+
+func BenchmarkDecodeResponse(b *testing.B) {
+	var markets, candles bytes.Buffer
+	markets.WriteString(`{"success":true,"result":[`)
+	candles.WriteString(`{"success":true,"result":[`)
+	for i := 0; i < 1000; i++ {
+		if i > 0 {
+			markets.WriteByte(',')
+			candles.WriteByte(',')
+		}
+		fmt.Fprintf(&markets, `{"name":"M%d-PERP","baseCurrency":null,"quoteCurrency":null,"type":"future","underlying":"M%d","enabled":true,"ask":3949.25,"bid":3949,"last":10579.52,"postOnly":false,"priceIncrement":0.25,"sizeIncrement":0.001,"restricted":false}`, i, i)
+		fmt.Fprintf(&candles, `{"close":11055.25,"high":11089.0,"low":11043.5,"open":11059.25,"startTime":"2019-06-24T17:15:00+00:00","volume":464193.95725}`)
+	}
+	markets.WriteString(`]}`)
+	candles.WriteString(`]}`)
+
+	for name, body := range map[string][]byte{"markets": markets.Bytes(), "candles": candles.Bytes()} {
+		newOut := func() interface{} {
+			if name == "markets" {
+				return &[]Market{}
+			}
+			return &[]Candle{}
+		}
+
+		b.Run(name+"/Response", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for i := 0; i < b.N; i++ {
+				if err := decodeResponseReflect(body, newOut()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(name+"/decodeResponse", func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for i := 0; i < b.N; i++ {
+				if err := decodeResponse(http.StatusOK, body, newOut()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+// Package jsonscan parses JSON in place, without reflection or allocations,
+// for the hot paths that can't afford encoding/json.
+package jsonscan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Scanner is a minimal JSON parser working on the bytes of one message.
+type Scanner struct {
+	b []byte
+	i int
+}
+
+func New(b []byte) *Scanner {
+	return &Scanner{b: b}
+}
+
+// Pos returns the offset of the next byte to be scanned.
+func (s *Scanner) Pos() int {
+	return s.i
+}
+
+func (s *Scanner) error() error {
+	if s.i >= len(s.b) {
+		return errors.New("unexpected end of JSON input")
+	}
+	return fmt.Errorf("invalid character %q at offset %d", s.b[s.i], s.i)
+}
+
+// WS skips white space.
+func (s *Scanner) WS() {
+	for s.i < len(s.b) {
+		switch s.b[s.i] {
+		case ' ', '\t', '\n', '\r':
+			s.i++
+		default:
+			return
+		}
+	}
+}
+
+func (s *Scanner) consume(c byte) bool {
+	s.WS()
+	if s.i < len(s.b) && s.b[s.i] == c {
+		s.i++
+		return true
+	}
+	return false
+}
+
+func (s *Scanner) expect(c byte) error {
+	if !s.consume(c) {
+		return s.error()
+	}
+	return nil
+}
+
+func (s *Scanner) literal(lit string) bool {
+	s.WS()
+	if len(s.b)-s.i >= len(lit) && string(s.b[s.i:s.i+len(lit)]) == lit {
+		s.i += len(lit)
+		return true
+	}
+	return false
+}
+
+// Object calls f for every key, with s positioned at its value. f must consume the value.
+func (s *Scanner) Object(f func(key []byte) error) error {
+	if s.literal("null") {
+		return nil
+	}
+	if err := s.expect('{'); err != nil {
+		return err
+	}
+	if s.consume('}') {
+		return nil
+	}
+	for {
+		key, err := s.Str()
+		if err != nil {
+			return err
+		}
+		if err := s.expect(':'); err != nil {
+			return err
+		}
+		if err := f(key); err != nil {
+			return err
+		}
+		if s.consume(',') {
+			continue
+		}
+		return s.expect('}')
+	}
+}
+
+// Array calls f for every element. f must consume the element.
+func (s *Scanner) Array(f func() error) error {
+	if s.literal("null") {
+		return nil
+	}
+	if err := s.expect('['); err != nil {
+		return err
+	}
+	if s.consume(']') {
+		return nil
+	}
+	for {
+		if err := f(); err != nil {
+			return err
+		}
+		if s.consume(',') {
+			continue
+		}
+		return s.expect(']')
+	}
+}
+
+// Str returns the contents of a string, or nil for null. The result aliases
+// the message unless the string contains escapes.
+func (s *Scanner) Str() ([]byte, error) {
+	if s.literal("null") {
+		return nil, nil
+	}
+	if err := s.expect('"'); err != nil {
+		return nil, err
+	}
+	start, escaped := s.i, false
+	for s.i < len(s.b) {
+		switch s.b[s.i] {
+		case '\\':
+			escaped = true
+			s.i += 2
+			continue
+		case '"':
+			s.i++
+			if !escaped {
+				return s.b[start : s.i-1], nil
+			}
+			var v string
+			if err := json.Unmarshal(s.b[start-1:s.i], &v); err != nil {
+				return nil, err
+			}
+			return []byte(v), nil
+		}
+		s.i++
+	}
+	return nil, s.error()
+}
+
+// Null consumes a null and reports whether there was one.
+func (s *Scanner) Null() bool {
+	return s.literal("null")
+}
+
+// Number returns the bytes of a number, or nil for null.
+func (s *Scanner) Number() ([]byte, error) {
+	if s.literal("null") {
+		return nil, nil
+	}
+	start := s.i
+	for s.i < len(s.b) {
+		c := s.b[s.i]
+		if (c < '0' || c > '9') && c != '-' && c != '+' && c != '.' && c != 'e' && c != 'E' {
+			break
+		}
+		s.i++
+	}
+	if s.i == start {
+		return nil, s.error()
+	}
+	return s.b[start:s.i], nil
+}
+
+func (s *Scanner) Float() (float64, error) {
+	n, err := s.Number()
+	if err != nil || n == nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(n), 64)
+}
+
+func (s *Scanner) Int() (int, error) {
+	n, err := s.Number()
+	if err != nil || n == nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(n))
+}
+
+func (s *Scanner) Bool() (bool, error) {
+	switch {
+	case s.literal("true"):
+		return true, nil
+	case s.literal("false"), s.literal("null"):
+		return false, nil
+	}
+	return false, s.error()
+}
+
+// Skip consumes a value.
+func (s *Scanner) Skip() error {
+	s.WS()
+	if s.i >= len(s.b) {
+		return s.error()
+	}
+	switch s.b[s.i] {
+	case '"':
+		_, err := s.Str()
+		return err
+	case '{':
+		return s.Object(func([]byte) error { return s.Skip() })
+	case '[':
+		return s.Array(s.Skip)
+	case 't', 'f':
+		_, err := s.Bool()
+		return err
+	case 'n':
+		if s.literal("null") {
+			return nil
+		}
+		return s.error()
+	default:
+		_, err := s.Number()
+		return err
+	}
+}
+
+// Time parses an RFC 3339 string. Times with the usual layout are returned in
+// UTC without allocating; time.Parse allocates a location for every numeric offset.
+func (s *Scanner) Time() (time.Time, error) {
+	b, err := s.Str()
+	if err != nil || b == nil {
+		return time.Time{}, err
+	}
+	if t, ok := parseTime(b); ok {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, string(b))
+}
+
+// parseTime parses RFC 3339 times like 2021-09-01T12:34:56.789+00:00 into UTC.
+func parseTime(b []byte) (time.Time, bool) {
+	num := func(b []byte) (int, bool) {
+		n := 0
+		for _, c := range b {
+			if c < '0' || c > '9' {
+				return 0, false
+			}
+			n = n*10 + int(c-'0')
+		}
+		return n, true
+	}
+
+	if len(b) < 20 || b[4] != '-' || b[7] != '-' || b[10] != 'T' || b[13] != ':' || b[16] != ':' {
+		return time.Time{}, false
+	}
+	year, ok1 := num(b[0:4])
+	month, ok2 := num(b[5:7])
+	day, ok3 := num(b[8:10])
+	hour, ok4 := num(b[11:13])
+	min, ok5 := num(b[14:16])
+	sec, ok6 := num(b[17:19])
+	if !(ok1 && ok2 && ok3 && ok4 && ok5 && ok6) {
+		return time.Time{}, false
+	}
+
+	i, nsec := 19, 0
+	if b[i] == '.' {
+		i++
+		digits := 0
+		for ; i < len(b) && b[i] >= '0' && b[i] <= '9'; i++ {
+			if digits < 9 {
+				nsec = nsec*10 + int(b[i]-'0')
+				digits++
+			}
+		}
+		for ; digits < 9; digits++ {
+			nsec *= 10
+		}
+	}
+
+	var offset int
+	switch {
+	case i == len(b)-1 && b[i] == 'Z':
+	case i == len(b)-6 && (b[i] == '+' || b[i] == '-') && b[i+3] == ':':
+		h, okh := num(b[i+1 : i+3])
+		m, okm := num(b[i+4 : i+6])
+		if !okh || !okm {
+			return time.Time{}, false
+		}
+		offset = h*3600 + m*60
+		if b[i] == '-' {
+			offset = -offset
+		}
+	default:
+		return time.Time{}, false
+	}
+
+	t := time.Date(year, time.Month(month), day, hour, min, sec, nsec, time.UTC)
+	return t.Add(-time.Duration(offset) * time.Second), true
+}
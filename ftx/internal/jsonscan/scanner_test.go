@@ -0,0 +1,69 @@
+package jsonscan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanner(t *testing.T) {
+	s := New([]byte(` {"a": "x\"y", "b": [1, -2.5e3, null], "c": {"d": [true, {}]}, "e": false, "f": null} `))
+
+	got := map[string]interface{}{}
+	err := s.Object(func(key []byte) error {
+		switch string(key) {
+		case "a":
+			b, err := s.Str()
+			got["a"] = string(b)
+			return err
+		case "b":
+			var floats []float64
+			err := s.Array(func() error {
+				f, err := s.Float()
+				floats = append(floats, f)
+				return err
+			})
+			got["b"] = floats
+			return err
+		case "e":
+			b, err := s.Bool()
+			got["e"] = b
+			return err
+		case "f":
+			got["f"] = s.Null()
+			return nil
+		}
+		return s.Skip()
+	})
+	s.WS()
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": `x"y`, "b": []float64{1, -2500, 0}, "e": false, "f": true}, got)
+	assert.Equal(t, len(s.b), s.Pos())
+}
+
+func TestScanner_Invalid(t *testing.T) {
+	for _, in := range []string{`{"a" 1}`, `{"a": [1, 2}`, `[1,`, `"abc`, `{"a": tru}`} {
+		assert.Error(t, New([]byte(in)).Skip(), in)
+	}
+}
+
+func TestScanner_Time(t *testing.T) {
+	for _, in := range []string{
+		`"2019-03-20T18:16:23.397991+00:00"`,
+		`"2021-09-01T08:00:00+08:00"`,
+		`"2021-09-01T00:00:00Z"`,
+		`"2021-09-01T00:00:00.5-05:30"`,
+	} {
+		want, err := time.Parse(time.RFC3339Nano, in[1:len(in)-1])
+		assert.NoError(t, err)
+
+		got, err := New([]byte(in)).Time()
+		assert.NoError(t, err)
+		assert.True(t, want.Equal(got), in)
+	}
+
+	_, err := New([]byte(`"yesterday"`)).Time()
+	assert.Error(t, err)
+}
@@ -0,0 +1,133 @@
+package ftx
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type LeveragedTokenService service
+
+const (
+	pathLeveragedTokens      = "%s/lt/tokens"
+	pathLeveragedToken       = "%s/lt/%s"
+	pathLeveragedBalances    = "%s/lt/balances"
+	pathLeveragedCreations   = "%s/lt/creations"
+	pathLeveragedCreate      = "%s/lt/%s/create"
+	pathLeveragedRedemptions = "%s/lt/redemptions"
+	pathLeveragedRedeem      = "%s/lt/%s/redeem"
+)
+
+type LeveragedToken struct {
+	Name             string  `json:"name"`
+	Description      string  `json:"description"`
+	Underlying       string  `json:"underlying"`
+	Leverage         float64 `json:"leverage"`
+	Outstanding      float64 `json:"outstanding"`
+	PricePerShare    float64 `json:"pricePerShare"`
+	PositionPerShare float64 `json:"positionPerShare"`
+	UnderlyingMark   float64 `json:"underlyingMark"`
+	ContractAddress  string  `json:"contractAddress"`
+	Change1h         float64 `json:"change1h"`
+	Change24h        float64 `json:"change24h"`
+}
+
+// All FTX API docs: https://docs.ftx.com/#list-leveraged-tokens
+func (s *LeveragedTokenService) All() ([]LeveragedToken, error) {
+	u := fmt.Sprintf(pathLeveragedTokens, s.client.baseURL)
+
+	var out []LeveragedToken
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// Get FTX API docs: https://docs.ftx.com/#get-token-info
+func (s *LeveragedTokenService) Get(name string) (*LeveragedToken, error) {
+	u := fmt.Sprintf(pathLeveragedToken, s.client.baseURL, name)
+
+	var out LeveragedToken
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return &out, err
+}
+
+type LeveragedTokenBalance struct {
+	Token   string  `json:"token"`
+	Balance float64 `json:"balance"`
+}
+
+// GetBalances FTX API docs: https://docs.ftx.com/#get-leveraged-token-balances
+func (s *LeveragedTokenService) GetBalances() ([]LeveragedTokenBalance, error) {
+	u := fmt.Sprintf(pathLeveragedBalances, s.client.baseURL)
+
+	var out []LeveragedTokenBalance
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type LeveragedTokenCreation struct {
+	ID            int        `json:"id"`
+	Token         string     `json:"token"`
+	RequestedSize float64    `json:"requestedSize"`
+	Pending       bool       `json:"pending"`
+	CreatedSize   float64    `json:"createdSize"`
+	Price         float64    `json:"price"`
+	Cost          float64    `json:"cost"`
+	Fee           float64    `json:"fee"`
+	RequestedAt   time.Time  `json:"requestedAt"`
+	FulfilledAt   *time.Time `json:"fulfilledAt"`
+}
+
+// GetCreationRequests FTX API docs: https://docs.ftx.com/#list-leveraged-token-creation-requests
+func (s *LeveragedTokenService) GetCreationRequests() ([]LeveragedTokenCreation, error) {
+	u := fmt.Sprintf(pathLeveragedCreations, s.client.baseURL)
+
+	var out []LeveragedTokenCreation
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type RequestLeveragedTokenSize struct {
+	Size float64 `json:"size"`
+}
+
+// RequestCreation FTX API docs: https://docs.ftx.com/#request-leveraged-token-creation
+func (s *LeveragedTokenService) RequestCreation(name string, size float64) (*LeveragedTokenCreation, error) {
+	u := fmt.Sprintf(pathLeveragedCreate, s.client.baseURL, name)
+
+	in := RequestLeveragedTokenSize{Size: size}
+	var out LeveragedTokenCreation
+	err := s.client.DoPrivate(u, http.MethodPost, &in, &out)
+	return &out, err
+}
+
+type LeveragedTokenRedemption struct {
+	ID                int        `json:"id"`
+	Token             string     `json:"token"`
+	Size              float64    `json:"size"`
+	ProjectedProceeds float64    `json:"projectedProceeds"`
+	Pending           bool       `json:"pending"`
+	Price             float64    `json:"price"`
+	Proceeds          float64    `json:"proceeds"`
+	Fee               float64    `json:"fee"`
+	RequestedAt       time.Time  `json:"requestedAt"`
+	FulfilledAt       *time.Time `json:"fulfilledAt"`
+}
+
+// GetRedemptionRequests FTX API docs: https://docs.ftx.com/#list-leveraged-token-redemption-requests
+func (s *LeveragedTokenService) GetRedemptionRequests() ([]LeveragedTokenRedemption, error) {
+	u := fmt.Sprintf(pathLeveragedRedemptions, s.client.baseURL)
+
+	var out []LeveragedTokenRedemption
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// RequestRedemption FTX API docs: https://docs.ftx.com/#request-leveraged-token-redemption
+func (s *LeveragedTokenService) RequestRedemption(name string, size float64) (*LeveragedTokenRedemption, error) {
+	u := fmt.Sprintf(pathLeveragedRedeem, s.client.baseURL, name)
+
+	in := RequestLeveragedTokenSize{Size: size}
+	var out LeveragedTokenRedemption
+	err := s.client.DoPrivate(u, http.MethodPost, &in, &out)
+	return &out, err
+}
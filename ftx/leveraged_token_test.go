@@ -0,0 +1,125 @@
+package ftx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestLeveragedTokenService_All(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"name":"HEDGE","underlying":"BTC-PERP","leverage":-1,"pricePerShare":1829.5}]}`)
+	}
+
+	tokens, err := c.LeveragedTokens.All()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "HEDGE", tokens[0].Name)
+	assert.Equal(t, float64(-1), tokens[0].Leverage)
+}
+
+func TestLeveragedTokenService_Get(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":{"name":"HEDGE","outstanding":1000.0}}`)
+	}
+
+	token, err := c.LeveragedTokens.Get("HEDGE")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/lt/HEDGE", <-ch)
+	assert.Equal(t, float64(1000), token.Outstanding)
+}
+
+func TestLeveragedTokenService_GetBalances(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"token":"HEDGE","balance":1.8}]}`)
+	}
+
+	balances, err := c.LeveragedTokens.GetBalances()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.8, balances[0].Balance)
+}
+
+func TestLeveragedTokenService_GetCreationRequests(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":123,"token":"HEDGE","requestedSize":0.01,"pending":false,"createdSize":0.01,"price":1234.5,"cost":12.345,"fee":0.01,"requestedAt":"2019-03-05T09:56:55.728933+00:00","fulfilledAt":"2019-03-05T09:56:55.728933+00:00"}]}`)
+	}
+
+	creations, err := c.LeveragedTokens.GetCreationRequests()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 123, creations[0].ID)
+	assert.NotNil(t, creations[0].FulfilledAt)
+}
+
+func TestLeveragedTokenService_RequestCreation(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 2)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":123,"token":"HEDGE","requestedSize":10,"cost":10.05,"pending":true,"requestedAt":"2019-03-05T09:56:55.728933+00:00"}}`)
+	}
+
+	creation, err := c.LeveragedTokens.RequestCreation("HEDGE", 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/lt/HEDGE/create", <-ch)
+	assert.JSONEq(t, `{"size":10}`, <-ch)
+	assert.True(t, creation.Pending)
+	assert.Nil(t, creation.FulfilledAt)
+}
+
+func TestLeveragedTokenService_GetRedemptionRequests(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":123,"token":"HEDGE","size":12.35,"projectedProceeds":123.5,"pending":false,"requestedAt":"2019-03-05T09:56:55.728933+00:00","fulfilledAt":"2019-03-05T09:56:55.728933+00:00"}]}`)
+	}
+
+	redemptions, err := c.LeveragedTokens.GetRedemptionRequests()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 123.5, redemptions[0].ProjectedProceeds)
+}
+
+func TestLeveragedTokenService_RequestRedemption(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 2)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":123,"token":"HEDGE","size":10,"projectedProceeds":10.05,"pending":true,"requestedAt":"2019-03-05T09:56:55.728933+00:00"}}`)
+	}
+
+	redemption, err := c.LeveragedTokens.RequestRedemption("HEDGE", 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/lt/HEDGE/redeem", <-ch)
+	assert.JSONEq(t, `{"size":10}`, <-ch)
+	assert.Equal(t, 10.05, redemption.ProjectedProceeds)
+}
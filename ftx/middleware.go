@@ -0,0 +1,184 @@
+package ftx
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DoFunc sends a signed request and reads its response.
+type DoFunc func(req *fasthttp.Request, resp *fasthttp.Response) error
+
+// Middleware wraps the sending of every request made by Client, e.g. for
+// logging, metrics or tracing. Requests are already signed when they reach it.
+type Middleware func(next DoFunc) DoFunc
+
+type RequestInfo struct {
+	Method   string
+	URL      string
+	Path     string
+	Status   int
+	Latency  time.Duration
+	BytesOut int
+	BytesIn  int
+	Err      error
+}
+
+// Observe returns a Middleware calling f with the outcome of every request.
+func Observe(f func(info RequestInfo)) Middleware {
+	return func(next DoFunc) DoFunc {
+		return func(req *fasthttp.Request, resp *fasthttp.Response) error {
+			start := time.Now()
+			err := next(req, resp)
+
+			info := RequestInfo{
+				Method:   string(req.Header.Method()),
+				URL:      req.URI().String(),
+				Path:     string(req.URI().Path()),
+				Latency:  time.Since(start),
+				BytesOut: len(req.Body()),
+				Err:      err,
+			}
+			if err == nil {
+				info.Status = resp.StatusCode()
+				info.BytesIn = len(resp.Body())
+			}
+			f(info)
+			return err
+		}
+	}
+}
+
+// Logger is a structured logger receiving alternating keys and values.
+type Logger interface {
+	Log(keyvals ...interface{})
+}
+
+const redacted = "[REDACTED]"
+
+// LoggingMiddleware logs every request. The API key and signature headers are
+// redacted.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next DoFunc) DoFunc {
+		return func(req *fasthttp.Request, resp *fasthttp.Response) error {
+			start := time.Now()
+			err := next(req, resp)
+
+			headers := make(map[string]string)
+			req.Header.VisitAll(func(k, v []byte) {
+				key := string(k)
+				if strings.EqualFold(key, HeaderKey) || strings.EqualFold(key, HeaderSign) {
+					headers[key] = redacted
+					return
+				}
+				headers[key] = string(v)
+			})
+
+			keyvals := []interface{}{
+				"method", string(req.Header.Method()),
+				"url", req.URI().String(),
+				"headers", headers,
+				"latency", time.Since(start),
+				"bytes_out", len(req.Body()),
+			}
+			if err != nil {
+				keyvals = append(keyvals, "err", err)
+			} else {
+				keyvals = append(keyvals, "status", resp.StatusCode(), "bytes_in", len(resp.Body()))
+			}
+			logger.Log(keyvals...)
+			return err
+		}
+	}
+}
+
+// Counter is a counter vector, e.g. backed by a prometheus.CounterVec.
+type Counter interface {
+	Add(v float64, labelValues ...string)
+}
+
+// Histogram is a histogram vector, e.g. backed by a prometheus.HistogramVec.
+type Histogram interface {
+	Observe(v float64, labelValues ...string)
+}
+
+type CounterFunc func(v float64, labelValues ...string)
+
+func (f CounterFunc) Add(v float64, labelValues ...string) { f(v, labelValues...) }
+
+type HistogramFunc func(v float64, labelValues ...string)
+
+func (f HistogramFunc) Observe(v float64, labelValues ...string) { f(v, labelValues...) }
+
+// Metrics are recorded by MetricsMiddleware. Any of them may be nil.
+type Metrics struct {
+	// Requests is labeled by method, route and status code, "error" if the request failed.
+	Requests Counter
+	// Latency is in seconds and labeled by method and route.
+	Latency Histogram
+	// Bytes is labeled by method, route and direction, "in" or "out".
+	Bytes Counter
+	// Route returns the route label of a request, RouteTemplate of its path by default.
+	Route func(info *RequestInfo) string
+}
+
+func MetricsMiddleware(m Metrics) Middleware {
+	return Observe(func(info RequestInfo) {
+		status := "error"
+		if info.Err == nil {
+			status = strconv.Itoa(info.Status)
+		}
+		route := RouteTemplate(info.Path)
+		if m.Route != nil {
+			route = m.Route(&info)
+		}
+		if m.Requests != nil {
+			m.Requests.Add(1, info.Method, route, status)
+		}
+		if m.Latency != nil {
+			m.Latency.Observe(info.Latency.Seconds(), info.Method, route)
+		}
+		if m.Bytes != nil {
+			m.Bytes.Add(float64(info.BytesOut), info.Method, route, "out")
+			m.Bytes.Add(float64(info.BytesIn), info.Method, route, "in")
+		}
+	})
+}
+
+// RouteTemplate replaces the variable segments of an API path so that it can
+// label metrics without growing their cardinality, e.g. /api/orders/123
+// becomes /api/orders/{id} and /api/markets/BTC/USD/orderbook becomes
+// /api/markets/{name}/orderbook. Segments of the API itself are lowercase words.
+func RouteTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	out := segments[:0]
+	for _, s := range segments {
+		switch {
+		case s == "" || isRouteWord(s):
+			out = append(out, s)
+		case strings.Trim(s, "0123456789") == "":
+			out = append(out, "{id}")
+		case len(out) > 0 && out[len(out)-1] == "{name}":
+			// The second half of a market name like BTC/USD.
+		default:
+			out = append(out, "{name}")
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+func isRouteWord(s string) bool {
+	letter := false
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z':
+			letter = true
+		case c >= '0' && c <= '9', c == '_':
+		default:
+			return false
+		}
+	}
+	return letter
+}
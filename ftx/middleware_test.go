@@ -0,0 +1,130 @@
+package ftx
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type testLogger struct {
+	keyvals []interface{}
+}
+
+func (l *testLogger) Log(keyvals ...interface{}) {
+	l.keyvals = keyvals
+}
+
+func TestWithMiddleware(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":null}`)
+	}
+
+	var order []string
+	mw := func(name string) Middleware {
+		return func(next DoFunc) DoFunc {
+			return func(req *fasthttp.Request, resp *fasthttp.Response) error {
+				order = append(order, name)
+				return next(req, resp)
+			}
+		}
+	}
+	c.middleware = []Middleware{mw("outer"), mw("inner")}
+
+	var info RequestInfo
+	WithMiddleware(Observe(func(i RequestInfo) { info = i }))(c)
+
+	err := c.DoPublic("http://example.com/markets", http.MethodPost, map[string]string{"foo": "bar"}, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer", "inner"}, order)
+	assert.Equal(t, http.MethodPost, info.Method)
+	assert.Equal(t, "/markets", info.Path)
+	assert.Equal(t, http.StatusOK, info.Status)
+	assert.Equal(t, len(`{"foo":"bar"}`+"\n"), info.BytesOut)
+	assert.Equal(t, len(`{"success":true,"result":null}`), info.BytesIn)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":null}`)
+	}
+
+	var logger testLogger
+	c.middleware = []Middleware{LoggingMiddleware(&logger)}
+
+	err := c.DoPrivate("http://example.com/account", http.MethodGet, nil, nil)
+	assert.NoError(t, err)
+
+	fields := make(map[string]interface{})
+	for i := 0; i < len(logger.keyvals); i += 2 {
+		fields[logger.keyvals[i].(string)] = logger.keyvals[i+1]
+	}
+	headers := fields["headers"].(map[string]string)
+
+	assert.Equal(t, "GET", fields["method"])
+	assert.Equal(t, http.StatusOK, fields["status"])
+	assert.Equal(t, redacted, headers["Ftx-Key"])
+	assert.Equal(t, redacted, headers["Ftx-Sign"])
+	assert.NotEmpty(t, headers["Ftx-Ts"])
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":null}`)
+	}
+
+	var requests, latencies [][]string
+	bytes := make(map[string]float64)
+	c.middleware = []Middleware{MetricsMiddleware(Metrics{
+		Requests: CounterFunc(func(v float64, labels ...string) { requests = append(requests, labels) }),
+		Latency:  HistogramFunc(func(v float64, labels ...string) { latencies = append(latencies, labels) }),
+		Bytes:    CounterFunc(func(v float64, labels ...string) { bytes[labels[2]] += v }),
+	})}
+
+	err := c.DoPublic("http://example.com/markets/BTC-PERP/orderbook", http.MethodGet, nil, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"GET", "/markets/{name}/orderbook", "200"}}, requests)
+	assert.Equal(t, [][]string{{"GET", "/markets/{name}/orderbook"}}, latencies)
+	assert.Equal(t, float64(0), bytes["out"])
+	assert.Equal(t, float64(len(`{"success":true,"result":null}`)), bytes["in"])
+
+	t.Run("route", func(t *testing.T) {
+		requests = nil
+		c.middleware = []Middleware{MetricsMiddleware(Metrics{
+			Requests: CounterFunc(func(v float64, labels ...string) { requests = append(requests, labels) }),
+			Route:    func(info *RequestInfo) string { return "custom" },
+		})}
+
+		assert.NoError(t, c.DoPublic("http://example.com/orders/123", http.MethodDelete, nil, nil))
+		assert.Equal(t, [][]string{{"DELETE", "custom", "200"}}, requests)
+	})
+}
+
+func TestRouteTemplate(t *testing.T) {
+	for path, want := range map[string]string{
+		"/api/markets":                        "/api/markets",
+		"/api/markets/BTC-PERP/orderbook":     "/api/markets/{name}/orderbook",
+		"/api/markets/BTC/USD/candles":        "/api/markets/{name}/candles",
+		"/api/orders/123456":                  "/api/orders/{id}",
+		"/api/options/24h_options_volume":     "/api/options/24h_options_volume",
+		"/api/lt/BULL/info":                   "/api/lt/{name}/info",
+		"/api/nft/balances":                   "/api/nft/balances",
+		"/api/nft/nft/1234/trades":            "/api/nft/nft/{id}/trades",
+		"/api/wallet/deposit_address/USDT":    "/api/wallet/deposit_address/{name}",
+		"/api/options/requests/42/quotes/7/x": "/api/options/requests/{id}/quotes/{id}/x",
+	} {
+		assert.Equal(t, want, RouteTemplate(path), path)
+	}
+}
@@ -0,0 +1,504 @@
+package ftx
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type NFTService service
+
+const (
+	pathNFTs             = "%s/nft/nfts"
+	pathNFT              = "%s/nft/nft/%d"
+	pathNFTTrades        = "%s/nft/%d/trades"
+	pathNFTAllTrades     = "%s/nft/all_trades"
+	pathNFTAccountInfo   = "%s/nft/%d/account_info"
+	pathNFTCollections   = "%s/nft/collections"
+	pathNFTBalances      = "%s/nft/balances"
+	pathNFTOffer         = "%s/nft/offer"
+	pathNFTBuy           = "%s/nft/buy"
+	pathNFTAuction       = "%s/nft/auction"
+	pathNFTEditAuction   = "%s/nft/edit_auction"
+	pathNFTCancelAuction = "%s/nft/cancel_auction"
+	pathNFTBids          = "%s/nft/bids"
+	pathNFTDeposits      = "%s/nft/deposits"
+	pathNFTWithdrawals   = "%s/nft/withdrawals"
+	pathNFTFills         = "%s/nft/fills"
+	pathNFTRedeem        = "%s/nft/redeem"
+)
+
+type NFT struct {
+	ID                 int               `json:"id"`
+	Name               string            `json:"name"`
+	Description        string            `json:"description"`
+	Issuer             string            `json:"issuer"`
+	Collection         string            `json:"collection"`
+	Series             string            `json:"series"`
+	Number             int               `json:"number"`
+	TotalQuantity      int               `json:"totalQuantity"`
+	QuoteCurrency      string            `json:"quoteCurrency"`
+	OfferPrice         *float64          `json:"offerPrice"`
+	Auction            *NFTAuction       `json:"auction"`
+	Attributes         map[string]string `json:"attributes"`
+	Redeemable         bool              `json:"redeemable"`
+	Redeemed           bool              `json:"redeemed"`
+	SolMintAddress     string            `json:"solMintAddress"`
+	EthContractAddress string            `json:"ethContractAddress"`
+	ImageURL           string            `json:"imageUrl"`
+	VideoURL           string            `json:"videoUrl"`
+	AnimationURL       string            `json:"animationUrl"`
+	ThumbnailURL       string            `json:"thumbnailUrl"`
+}
+
+type NFTAuction struct {
+	BestBid    *float64  `json:"bestBid"`
+	MinNextBid float64   `json:"minNextBid"`
+	EndTime    time.Time `json:"endTime"`
+	Bids       int       `json:"bids"`
+}
+
+// All FTX API docs: https://docs.ftx.com/#list-nfts
+func (s *NFTService) All() ([]NFT, error) {
+	u := fmt.Sprintf(pathNFTs, s.client.baseURL)
+
+	var out []NFT
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// Get FTX API docs: https://docs.ftx.com/#get-nft-info
+func (s *NFTService) Get(id int) (*NFT, error) {
+	u := fmt.Sprintf(pathNFT, s.client.baseURL, id)
+
+	var out NFT
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return &out, err
+}
+
+// GetNFTHistoryOptions pages through history. Results are returned newest
+// first, so older pages are requested by moving EndTime backwards.
+type GetNFTHistoryOptions struct {
+	StartTime int64 `url:"start_time,omitempty"`
+	EndTime   int64 `url:"end_time,omitempty"`
+}
+
+type NFTTrade struct {
+	ID    int       `json:"id"`
+	NFT   *NFT      `json:"nft,omitempty"`
+	Price float64   `json:"price"`
+	Time  time.Time `json:"time"`
+}
+
+// GetTrades FTX API docs: https://docs.ftx.com/#get-nft-trades
+func (s *NFTService) GetTrades(id int, opts *GetNFTHistoryOptions) ([]NFTTrade, error) {
+	u := fmt.Sprintf(pathNFTTrades, s.client.baseURL, id)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []NFTTrade
+	err = s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// GetTradesPages calls GetTrades for successively older pages and passes
+// each page to f until the history is exhausted or f returns false. Trades
+// repeated across page boundaries are passed only once.
+func (s *NFTService) GetTradesPages(id int, opts *GetNFTHistoryOptions, f func([]NFTTrade) bool) error {
+	p := newTimePager(opts)
+	for {
+		trades, err := s.GetTrades(id, &p.opts)
+		if err != nil {
+			return err
+		}
+		page := trades[:0]
+		for _, t := range trades {
+			if p.add(t.ID, t.Time) {
+				page = append(page, t)
+			}
+		}
+		if len(page) > 0 && !f(page) || !p.next() {
+			return nil
+		}
+	}
+}
+
+// GetAllTrades FTX API docs: https://docs.ftx.com/#get-all-nft-trades
+func (s *NFTService) GetAllTrades(opts *GetNFTHistoryOptions) ([]NFTTrade, error) {
+	u := fmt.Sprintf(pathNFTAllTrades, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []NFTTrade
+	err = s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// GetAllTradesPages calls GetAllTrades for successively older pages and passes
+// each page to f until the history is exhausted or f returns false. Trades
+// repeated across page boundaries are passed only once.
+func (s *NFTService) GetAllTradesPages(opts *GetNFTHistoryOptions, f func([]NFTTrade) bool) error {
+	p := newTimePager(opts)
+	for {
+		trades, err := s.GetAllTrades(&p.opts)
+		if err != nil {
+			return err
+		}
+		page := trades[:0]
+		for _, t := range trades {
+			if p.add(t.ID, t.Time) {
+				page = append(page, t)
+			}
+		}
+		if len(page) > 0 && !f(page) || !p.next() {
+			return nil
+		}
+	}
+}
+
+type NFTAccountInfo struct {
+	Bid       *float64 `json:"bid"`
+	BuyFee    float64  `json:"buyFee"`
+	IsBestBid bool     `json:"isBestBid"`
+	Owned     bool     `json:"owned"`
+}
+
+// GetAccountInfo FTX API docs: https://docs.ftx.com/#get-nft-account-info
+func (s *NFTService) GetAccountInfo(id int) (*NFTAccountInfo, error) {
+	u := fmt.Sprintf(pathNFTAccountInfo, s.client.baseURL, id)
+
+	var out NFTAccountInfo
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return &out, err
+}
+
+type NFTCollection struct {
+	Issuer     string `json:"issuer"`
+	Collection string `json:"collection"`
+}
+
+// GetCollections FTX API docs: https://docs.ftx.com/#get-all-nft-collections
+func (s *NFTService) GetCollections() ([]NFTCollection, error) {
+	u := fmt.Sprintf(pathNFTCollections, s.client.baseURL)
+
+	var out []NFTCollection
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// GetBalances FTX API docs: https://docs.ftx.com/#get-nft-balances
+func (s *NFTService) GetBalances() ([]NFT, error) {
+	u := fmt.Sprintf(pathNFTBalances, s.client.baseURL)
+
+	var out []NFT
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type NFTPricePayload struct {
+	NFTID int     `json:"nftId"`
+	Price float64 `json:"price"`
+}
+
+// MakeOffer FTX API docs: https://docs.ftx.com/#make-nft-offer
+func (s *NFTService) MakeOffer(in *NFTPricePayload) (*NFT, error) {
+	u := fmt.Sprintf(pathNFTOffer, s.client.baseURL)
+
+	var out NFT
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+// Buy FTX API docs: https://docs.ftx.com/#buy-nft
+func (s *NFTService) Buy(in *NFTPricePayload) (*NFT, error) {
+	u := fmt.Sprintf(pathNFTBuy, s.client.baseURL)
+
+	var out NFT
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+type CreateNFTAuctionPayload struct {
+	NFTID            int     `json:"nftId"`
+	InitialPrice     float64 `json:"initialPrice"`
+	ReservationPrice float64 `json:"reservationPrice"`
+	Duration         int     `json:"duration"` // in seconds
+}
+
+// CreateAuction FTX API docs: https://docs.ftx.com/#create-auction
+func (s *NFTService) CreateAuction(in *CreateNFTAuctionPayload) (*NFT, error) {
+	u := fmt.Sprintf(pathNFTAuction, s.client.baseURL)
+
+	var out NFT
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+type EditNFTAuctionPayload struct {
+	NFTID            int     `json:"nftId"`
+	ReservationPrice float64 `json:"reservationPrice"`
+}
+
+// EditAuction FTX API docs: https://docs.ftx.com/#edit-auction
+func (s *NFTService) EditAuction(in *EditNFTAuctionPayload) (*NFT, error) {
+	u := fmt.Sprintf(pathNFTEditAuction, s.client.baseURL)
+
+	var out NFT
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+type CancelNFTAuctionPayload struct {
+	NFTID int `json:"nftId"`
+}
+
+// CancelAuction FTX API docs: https://docs.ftx.com/#cancel-auction
+func (s *NFTService) CancelAuction(id int) (*NFT, error) {
+	u := fmt.Sprintf(pathNFTCancelAuction, s.client.baseURL)
+
+	in := CancelNFTAuctionPayload{NFTID: id}
+	var out NFT
+	err := s.client.DoPrivate(u, http.MethodPost, &in, &out)
+	return &out, err
+}
+
+// GetBids FTX API docs: https://docs.ftx.com/#get-bids
+func (s *NFTService) GetBids() ([]NFT, error) {
+	u := fmt.Sprintf(pathNFTBids, s.client.baseURL)
+
+	var out []NFT
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// PlaceBid FTX API docs: https://docs.ftx.com/#place-bid
+func (s *NFTService) PlaceBid(in *NFTPricePayload) (*NFT, error) {
+	u := fmt.Sprintf(pathNFTBids, s.client.baseURL)
+
+	var out NFT
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+type NFTTransfer struct {
+	ID            int        `json:"id"`
+	NFT           *NFT       `json:"nft"`
+	Status        string     `json:"status"`
+	Address       string     `json:"address"`
+	Txid          string     `json:"txid"`
+	Fee           float64    `json:"fee"`
+	Confirmations int        `json:"confirmations"`
+	Time          time.Time  `json:"time"`
+	SentTime      *time.Time `json:"sentTime"`
+	ConfirmedTime *time.Time `json:"confirmedTime"`
+}
+
+// GetDeposits FTX API docs: https://docs.ftx.com/#get-nft-deposits
+func (s *NFTService) GetDeposits(opts *GetNFTHistoryOptions) ([]NFTTransfer, error) {
+	u := fmt.Sprintf(pathNFTDeposits, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []NFTTransfer
+	err = s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// GetWithdrawals FTX API docs: https://docs.ftx.com/#get-nft-withdrawals
+func (s *NFTService) GetWithdrawals(opts *GetNFTHistoryOptions) ([]NFTTransfer, error) {
+	u := fmt.Sprintf(pathNFTWithdrawals, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []NFTTransfer
+	err = s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// GetDepositsPages calls GetDeposits for successively older pages and passes
+// each page to f until the history is exhausted or f returns false. Deposits
+// repeated across page boundaries are passed only once.
+func (s *NFTService) GetDepositsPages(opts *GetNFTHistoryOptions, f func([]NFTTransfer) bool) error {
+	p := newTimePager(opts)
+	for {
+		deposits, err := s.GetDeposits(&p.opts)
+		if err != nil {
+			return err
+		}
+		page := deposits[:0]
+		for _, d := range deposits {
+			if p.add(d.ID, d.Time) {
+				page = append(page, d)
+			}
+		}
+		if len(page) > 0 && !f(page) || !p.next() {
+			return nil
+		}
+	}
+}
+
+// GetWithdrawalsPages calls GetWithdrawals for successively older pages and passes
+// each page to f until the history is exhausted or f returns false. Withdrawals
+// repeated across page boundaries are passed only once.
+func (s *NFTService) GetWithdrawalsPages(opts *GetNFTHistoryOptions, f func([]NFTTransfer) bool) error {
+	p := newTimePager(opts)
+	for {
+		withdrawals, err := s.GetWithdrawals(&p.opts)
+		if err != nil {
+			return err
+		}
+		page := withdrawals[:0]
+		for _, w := range withdrawals {
+			if p.add(w.ID, w.Time) {
+				page = append(page, w)
+			}
+		}
+		if len(page) > 0 && !f(page) || !p.next() {
+			return nil
+		}
+	}
+}
+
+type NFTFill struct {
+	ID            int       `json:"id"`
+	NFT           *NFT      `json:"nft"`
+	Side          string    `json:"side"`
+	Price         float64   `json:"price"`
+	Fee           float64   `json:"fee"`
+	QuoteCurrency string    `json:"quoteCurrency"`
+	Time          time.Time `json:"time"`
+}
+
+// GetFills FTX API docs: https://docs.ftx.com/#get-nft-fills
+func (s *NFTService) GetFills(opts *GetNFTHistoryOptions) ([]NFTFill, error) {
+	u := fmt.Sprintf(pathNFTFills, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []NFTFill
+	err = s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// GetFillsPages calls GetFills for successively older pages and passes each
+// page to f until the history is exhausted or f returns false. Fills repeated
+// across page boundaries are passed only once.
+func (s *NFTService) GetFillsPages(opts *GetNFTHistoryOptions, f func([]NFTFill) bool) error {
+	p := newTimePager(opts)
+	for {
+		fills, err := s.GetFills(&p.opts)
+		if err != nil {
+			return err
+		}
+		page := fills[:0]
+		for _, fill := range fills {
+			if p.add(fill.ID, fill.Time) {
+				page = append(page, fill)
+			}
+		}
+		if len(page) > 0 && !f(page) || !p.next() {
+			return nil
+		}
+	}
+}
+
+type RedeemNFTPayload struct {
+	NFTID   int    `json:"nftId"`
+	Address string `json:"address"`
+	Notes   string `json:"notes,omitempty"`
+}
+
+type NFTRedemption struct {
+	ID              int       `json:"id"`
+	NFT             *NFT      `json:"nft"`
+	Address         string    `json:"address"`
+	Notes           string    `json:"notes"`
+	Status          string    `json:"status"`
+	SupportTicketID int       `json:"supportTicketId"`
+	Time            time.Time `json:"time"`
+}
+
+// Redeem FTX API docs: https://docs.ftx.com/#redeem-nft
+func (s *NFTService) Redeem(in *RedeemNFTPayload) (*NFTRedemption, error) {
+	u := fmt.Sprintf(pathNFTRedeem, s.client.baseURL)
+
+	var out NFTRedemption
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+// timePager pages through history returned newest first by moving EndTime
+// back to the oldest entry of the previous page. The end time is inclusive, so
+// entries at the boundary are returned twice and skipped by add.
+type timePager struct {
+	opts GetNFTHistoryOptions
+	seen map[int]bool
+
+	// State of the current page.
+	n      int
+	fresh  int
+	oldest time.Time
+
+	// Size of the largest page, taken as the page limit.
+	limit int
+}
+
+func newTimePager(opts *GetNFTHistoryOptions) *timePager {
+	p := &timePager{seen: make(map[int]bool)}
+	if opts != nil {
+		p.opts = *opts
+	}
+	return p
+}
+
+// add records an entry of the current page and reports whether it is new.
+func (p *timePager) add(id int, t time.Time) bool {
+	p.n++
+	if p.oldest.IsZero() || t.Before(p.oldest) {
+		p.oldest = t
+	}
+	if p.seen[id] {
+		return false
+	}
+	p.seen[id] = true
+	p.fresh++
+	return true
+}
+
+// next moves to the page before the oldest entry of the current page and
+// reports whether there is one within StartTime. A full page of entries
+// already seen, all at EndTime, would be returned again: the rest of that
+// second can't be paged through, so it moves on to the second before.
+func (p *timePager) next() bool {
+	n, fresh, oldest := p.n, p.fresh, p.oldest
+	p.n, p.fresh, p.oldest = 0, 0, time.Time{}
+	if n > p.limit {
+		p.limit = n
+	}
+
+	if n == 0 {
+		return false
+	}
+	end := oldest.Unix()
+	if fresh == 0 {
+		if n < p.limit || end != p.opts.EndTime {
+			return false
+		}
+		end--
+	}
+	if p.opts.StartTime > 0 && end < p.opts.StartTime {
+		return false
+	}
+	p.opts.EndTime = end
+	return true
+}
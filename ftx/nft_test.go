@@ -0,0 +1,434 @@
+package ftx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestNFTService_All(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":123,"name":"Rare Ape","issuer":"FTX","collection":"Apes","quoteCurrency":"USD","offerPrice":null,"auction":{"bestBid":100.0,"minNextBid":105.0,"endTime":"2021-09-01T00:00:00+00:00","bids":3},"redeemable":true}]}`)
+	}
+
+	nfts, err := c.NFTs.All()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 123, nfts[0].ID)
+	assert.Nil(t, nfts[0].OfferPrice)
+	assert.Equal(t, 100.0, *nfts[0].Auction.BestBid)
+	assert.Equal(t, 3, nfts[0].Auction.Bids)
+}
+
+func TestNFTService_Get(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":{"id":123,"name":"Rare Ape","offerPrice":50.0}}`)
+	}
+
+	nft, err := c.NFTs.Get(123)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/nft/nft/123", <-ch)
+	assert.Equal(t, 50.0, *nft.OfferPrice)
+}
+
+func TestNFTService_GetTrades(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().RequestURI())
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"price":10.0,"time":"2021-09-01T00:00:00+00:00"}]}`)
+	}
+
+	trades, err := c.NFTs.GetTrades(123, &GetNFTHistoryOptions{StartTime: 1630000000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/nft/123/trades?start_time=1630000000", <-ch)
+	assert.Equal(t, 10.0, trades[0].Price)
+}
+
+func TestNFTService_GetTradesPages(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	var requests []string
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		requests = append(requests, string(ctx.Request.URI().RequestURI()))
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"price":1,"time":"2021-09-01T00:00:10+00:00"}]}`)
+	}
+
+	var ids []int
+	err := c.NFTs.GetTradesPages(123, nil, func(trades []NFTTrade) bool {
+		for _, t := range trades {
+			ids = append(ids, t.ID)
+		}
+		return true
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/nft/123/trades", "/nft/123/trades?end_time=1630454410", "/nft/123/trades?end_time=1630454409"}, requests)
+	assert.Equal(t, []int{1}, ids)
+}
+
+func TestNFTService_GetAllTradesPages(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	pages := map[string]string{
+		"":           `[{"id":3,"price":3,"time":"2021-09-01T00:00:30+00:00"},{"id":2,"price":2,"time":"2021-09-01T00:00:20+00:00"}]`,
+		"1630454420": `[{"id":2,"price":2,"time":"2021-09-01T00:00:20+00:00"},{"id":1,"price":1,"time":"2021-09-01T00:00:10+00:00"}]`,
+		"1630454410": `[{"id":1,"price":1,"time":"2021-09-01T00:00:10+00:00"}]`,
+	}
+	var requests []string
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		end := string(ctx.QueryArgs().Peek("end_time"))
+		requests = append(requests, end)
+		ctx.SetBodyString(fmt.Sprintf(`{"success":true,"result":%s}`, pages[end]))
+	}
+
+	var ids []int
+	err := c.NFTs.GetAllTradesPages(nil, func(trades []NFTTrade) bool {
+		for _, t := range trades {
+			ids = append(ids, t.ID)
+		}
+		return true
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "1630454420", "1630454410"}, requests)
+	assert.Equal(t, []int{3, 2, 1}, ids)
+}
+
+func TestNFTService_GetAllTradesPages_FullSecond(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	// A full page of trades in the same second is returned again for it.
+	full := `[{"id":3,"price":3,"time":"2021-09-01T00:00:10+00:00"},{"id":2,"price":2,"time":"2021-09-01T00:00:10+00:00"}]`
+	pages := map[string]string{
+		"":           full,
+		"1630454410": full,
+		"1630454409": `[{"id":1,"price":1,"time":"2021-09-01T00:00:05+00:00"}]`,
+		"1630454405": `[{"id":1,"price":1,"time":"2021-09-01T00:00:05+00:00"}]`,
+	}
+	var requests []string
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		end := string(ctx.QueryArgs().Peek("end_time"))
+		requests = append(requests, end)
+		ctx.SetBodyString(fmt.Sprintf(`{"success":true,"result":%s}`, pages[end]))
+	}
+
+	var ids []int
+	err := c.NFTs.GetAllTradesPages(nil, func(trades []NFTTrade) bool {
+		for _, t := range trades {
+			ids = append(ids, t.ID)
+		}
+		return true
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "1630454410", "1630454409", "1630454405"}, requests)
+	assert.Equal(t, []int{3, 2, 1}, ids)
+}
+
+func TestNFTService_GetAllTradesPages_Stop(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	calls := 0
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		calls++
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"price":1,"time":"2021-09-01T00:00:10+00:00"}]}`)
+	}
+
+	err := c.NFTs.GetAllTradesPages(nil, func(trades []NFTTrade) bool { return false })
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestNFTService_GetAccountInfo(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":{"bid":null,"buyFee":0.05,"isBestBid":false,"owned":true}}`)
+	}
+
+	info, err := c.NFTs.GetAccountInfo(123)
+
+	assert.NoError(t, err)
+	assert.Nil(t, info.Bid)
+	assert.True(t, info.Owned)
+}
+
+func TestNFTService_GetCollections(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"issuer":"FTX","collection":"Apes"}]}`)
+	}
+
+	collections, err := c.NFTs.GetCollections()
+
+	assert.NoError(t, err)
+	assert.Equal(t, NFTCollection{Issuer: "FTX", Collection: "Apes"}, collections[0])
+}
+
+func TestNFTService_GetBalances(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":123,"name":"Rare Ape"}]}`)
+	}
+
+	nfts, err := c.NFTs.GetBalances()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Rare Ape", nfts[0].Name)
+}
+
+func TestNFTService_Offers(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		call func(c *Client, in *NFTPricePayload) (*NFT, error)
+	}{
+		{"MakeOffer", "/nft/offer", func(c *Client, in *NFTPricePayload) (*NFT, error) { return c.NFTs.MakeOffer(in) }},
+		{"Buy", "/nft/buy", func(c *Client, in *NFTPricePayload) (*NFT, error) { return c.NFTs.Buy(in) }},
+		{"PlaceBid", "/nft/bids", func(c *Client, in *NFTPricePayload) (*NFT, error) { return c.NFTs.PlaceBid(in) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv, teardown := setup()
+			defer teardown()
+
+			ch := make(chan string, 2)
+
+			srv.Handler = func(ctx *fasthttp.RequestCtx) {
+				ch <- string(ctx.Request.Header.Method()) + " " + string(ctx.Request.URI().Path())
+				ch <- string(ctx.Request.Body())
+				ctx.SetBodyString(`{"success":true,"result":{"id":123}}`)
+			}
+
+			nft, err := tt.call(c, &NFTPricePayload{NFTID: 123, Price: 99.5})
+
+			assert.NoError(t, err)
+			assert.Equal(t, "POST "+tt.path, <-ch)
+			assert.JSONEq(t, `{"nftId":123,"price":99.5}`, <-ch)
+			assert.Equal(t, 123, nft.ID)
+		})
+	}
+}
+
+func TestNFTService_CreateAuction(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":123,"auction":{"bestBid":null,"minNextBid":10.0,"endTime":"2021-09-02T00:00:00+00:00","bids":0}}}`)
+	}
+
+	nft, err := c.NFTs.CreateAuction(&CreateNFTAuctionPayload{NFTID: 123, InitialPrice: 10, ReservationPrice: 20, Duration: 86400})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"nftId":123,"initialPrice":10,"reservationPrice":20,"duration":86400}`, <-ch)
+	assert.Nil(t, nft.Auction.BestBid)
+}
+
+func TestNFTService_EditAuction(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":123}}`)
+	}
+
+	_, err := c.NFTs.EditAuction(&EditNFTAuctionPayload{NFTID: 123, ReservationPrice: 15})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"nftId":123,"reservationPrice":15}`, <-ch)
+}
+
+func TestNFTService_CancelAuction(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 2)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":123}}`)
+	}
+
+	_, err := c.NFTs.CancelAuction(123)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/nft/cancel_auction", <-ch)
+	assert.JSONEq(t, `{"nftId":123}`, <-ch)
+}
+
+func TestNFTService_GetBids(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":123,"auction":{"bestBid":12.0,"minNextBid":13.0,"endTime":"2021-09-02T00:00:00+00:00","bids":1}}]}`)
+	}
+
+	nfts, err := c.NFTs.GetBids()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 13.0, nfts[0].Auction.MinNextBid)
+}
+
+func TestNFTService_GetDeposits(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().RequestURI())
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"nft":{"id":123},"status":"confirmed","time":"2021-09-01T00:00:00+00:00","sentTime":"2021-09-01T00:00:00+00:00","confirmedTime":"2021-09-01T00:01:00+00:00","confirmations":32}]}`)
+	}
+
+	deposits, err := c.NFTs.GetDeposits(&GetNFTHistoryOptions{StartTime: 1, EndTime: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/nft/deposits?end_time=2&start_time=1", <-ch)
+	assert.Equal(t, "confirmed", deposits[0].Status)
+	assert.Equal(t, 123, deposits[0].NFT.ID)
+	assert.NotNil(t, deposits[0].ConfirmedTime)
+}
+
+func TestNFTService_GetWithdrawals(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":2,"nft":{"id":123},"status":"requested","address":"0xabc","fee":0.1,"time":"2021-09-01T00:00:00+00:00","sentTime":null,"confirmedTime":null}]}`)
+	}
+
+	withdrawals, err := c.NFTs.GetWithdrawals(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0xabc", withdrawals[0].Address)
+	assert.Nil(t, withdrawals[0].SentTime)
+}
+
+func TestNFTService_TransferPages(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		pages func(c *Client, f func([]NFTTransfer) bool) error
+	}{
+		{"GetDepositsPages", "/nft/deposits", func(c *Client, f func([]NFTTransfer) bool) error {
+			return c.NFTs.GetDepositsPages(&GetNFTHistoryOptions{StartTime: 1630000000}, f)
+		}},
+		{"GetWithdrawalsPages", "/nft/withdrawals", func(c *Client, f func([]NFTTransfer) bool) error {
+			return c.NFTs.GetWithdrawalsPages(&GetNFTHistoryOptions{StartTime: 1630000000}, f)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, srv, teardown := setup()
+			defer teardown()
+
+			pages := map[string]string{
+				"":           `[{"id":2,"status":"confirmed","time":"2021-09-01T00:00:20+00:00"},{"id":1,"status":"confirmed","time":"2021-09-01T00:00:10+00:00"}]`,
+				"1630454410": `[{"id":1,"status":"confirmed","time":"2021-09-01T00:00:10+00:00"}]`,
+			}
+			var requests []string
+
+			srv.Handler = func(ctx *fasthttp.RequestCtx) {
+				requests = append(requests, string(ctx.Request.URI().RequestURI()))
+				ctx.SetBodyString(fmt.Sprintf(`{"success":true,"result":%s}`, pages[string(ctx.QueryArgs().Peek("end_time"))]))
+			}
+
+			var ids []int
+			err := tt.pages(c, func(transfers []NFTTransfer) bool {
+				for _, tr := range transfers {
+					ids = append(ids, tr.ID)
+				}
+				return true
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{tt.path + "?start_time=1630000000", tt.path + "?end_time=1630454410&start_time=1630000000"}, requests)
+			assert.Equal(t, []int{2, 1}, ids)
+		})
+	}
+}
+
+func TestNFTService_GetFillsPages(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	var requests []string
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		requests = append(requests, string(ctx.Request.URI().RequestURI()))
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"nft":{"id":123},"side":"buy","price":5.0,"quoteCurrency":"USD","time":"2021-09-01T00:00:10+00:00"}]}`)
+	}
+
+	var fills []NFTFill
+	err := c.NFTs.GetFillsPages(&GetNFTHistoryOptions{StartTime: 1630000000}, func(page []NFTFill) bool {
+		fills = append(fills, page...)
+		return true
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/nft/fills?start_time=1630000000",
+		"/nft/fills?end_time=1630454410&start_time=1630000000",
+		"/nft/fills?end_time=1630454409&start_time=1630000000",
+	}, requests)
+	assert.Len(t, fills, 1)
+	assert.Equal(t, "buy", fills[0].Side)
+}
+
+func TestNFTService_Redeem(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":7,"nft":{"id":123},"address":"1 Main St","status":"pending","supportTicketId":42,"time":"2021-09-01T00:00:00+00:00"}}`)
+	}
+
+	redemption, err := c.NFTs.Redeem(&RedeemNFTPayload{NFTID: 123, Address: "1 Main St"})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"nftId":123,"address":"1 Main St"}`, <-ch)
+	assert.Equal(t, 42, redemption.SupportTicketID)
+}
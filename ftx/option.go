@@ -1,18 +1,80 @@
 package ftx
 
-import "net/url"
+import (
+	"net/http"
+
+	"github.com/cloudingcity/go-ftx/ftx/auth"
+)
 
 type Option func(*Client)
 
+// WithAuth authenticates with key and signs requests with secret. Private
+// requests fail without being sent if either is empty.
 func WithAuth(key, secret string) Option {
 	return func(c *Client) {
 		c.key = key
-		c.secret = []byte(secret)
+		c.signer = nil
+		if secret != "" {
+			c.signer = auth.NewHMAC([]byte(secret))
+		}
+	}
+}
+
+// WithSigner authenticates with key and signs requests with signer instead of an in-memory secret.
+func WithSigner(key string, signer auth.Signer) Option {
+	return func(c *Client) {
+		c.key = key
+		c.signer = signer
 	}
 }
 
 func WithSubaccount(account string) Option {
 	return func(c *Client) {
-		c.subaccount = url.QueryEscape(account)
+		c.subaccount = account
+	}
+}
+
+// WithPaperTrading routes private requests to a local PaperExchange instead of FTX.
+// Public requests are still sent to the exchange.
+func WithPaperTrading(p *PaperExchange) Option {
+	return func(c *Client) {
+		c.paper = p
+	}
+}
+
+// WithClockSync keeps the clock offset used for request timestamps in line with
+// the Date header of the exchange responses. See also Client.SyncTime.
+func WithClockSync() Option {
+	return func(c *Client) {
+		c.clockSync = true
+	}
+}
+
+// WithMiddleware adds middleware around the sending of every request. The
+// first middleware is the outermost.
+func WithMiddleware(m ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, m...)
+	}
+}
+
+// WithTransport sends requests with t instead of the default fasthttp client.
+func WithTransport(t Transport) Option {
+	return func(c *Client) {
+		c.client = t
+	}
+}
+
+// WithHTTPClient sends requests with a net/http client. See NewHTTPTransport.
+func WithHTTPClient(client *http.Client) Option {
+	return WithTransport(NewHTTPTransport(client))
+}
+
+// WithCompression sets whether Connect negotiates permessage-deflate
+// compression, which cuts the bandwidth of busy feeds like orderbooks at the
+// cost of CPU. It is disabled by default.
+func WithCompression(enable bool) Option {
+	return func(c *Client) {
+		c.wsCompression = enable
 	}
 }
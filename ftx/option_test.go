@@ -1,9 +1,12 @@
 package ftx
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/cloudingcity/go-ftx/ftx/auth"
 	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
 )
 
 func TestWithAuth(t *testing.T) {
@@ -14,20 +17,35 @@ func TestWithAuth(t *testing.T) {
 	c := New(WithAuth(key, secret))
 
 	assert.Equal(t, key, c.key)
-	assert.Equal(t, []byte(secret), c.secret)
+	assert.Equal(t, auth.NewHMAC([]byte(secret)), c.signer)
+}
+
+func TestWithSigner(t *testing.T) {
+	signer := auth.SignerFunc(func(payload []byte) (string, error) {
+		return "", errors.New("signer unavailable")
+	})
+	c := New(WithSigner("api-key", signer))
+
+	assert.Equal(t, "api-key", c.key)
+	assert.EqualError(t, c.DoPrivate("http://example.com/", "GET", nil, nil), "signer unavailable")
 }
 
 func TestWithSubaccount(t *testing.T) {
 	tests := []struct {
 		account string
-		want    string
+		header  string
 	}{
-		{account: "my-account", want: "my-account"},
-		{account: "my/account", want: "my%2Faccount"},
+		{account: "my-account", header: "my-account"},
+		{account: "my/account", header: "my%2Faccount"},
 	}
 	for _, tt := range tests {
-		c := New(WithSubaccount(tt.account))
+		c := New(WithAuth("key", "secret"), WithSubaccount(tt.account))
+		req := fasthttp.AcquireRequest()
+		req.SetRequestURI("http://example.com/")
 
-		assert.Equal(t, tt.want, c.subaccount)
+		assert.NoError(t, c.auth(req))
+		assert.Equal(t, tt.account, c.subaccount)
+		assert.Equal(t, tt.header, string(req.Header.Peek(HeaderSubaccount)))
+		fasthttp.ReleaseRequest(req)
 	}
 }
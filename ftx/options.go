@@ -0,0 +1,307 @@
+package ftx
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type OptionsService service
+
+const (
+	pathOptionsRequests         = "%s/options/requests"
+	pathOptionsRequest          = "%s/options/requests/%d"
+	pathOptionsRequestQuotes    = "%s/options/requests/%d/quotes"
+	pathOptionsMyRequests       = "%s/options/my_requests"
+	pathOptionsMyQuotes         = "%s/options/my_quotes"
+	pathOptionsQuote            = "%s/options/quotes/%d"
+	pathOptionsQuoteAccept      = "%s/options/quotes/%d/accept"
+	pathOptionsAccountInfo      = "%s/options/account_info"
+	pathOptionsPositions        = "%s/options/positions"
+	pathOptionsTrades           = "%s/options/trades"
+	pathOptionsFills            = "%s/options/fills"
+	pathOptions24hVolume        = "%s/stats/24h_options_volume"
+	pathOptionsOpenInterest     = "%s/options/open_interest/%s"
+	pathOptionsHistoricalVolume = "%s/options/historical_volumes/%s"
+)
+
+type OptionType string
+
+const (
+	OptionTypeCall OptionType = "call"
+	OptionTypePut  OptionType = "put"
+)
+
+type OptionSide string
+
+const (
+	OptionSideBuy  OptionSide = "buy"
+	OptionSideSell OptionSide = "sell"
+)
+
+type OptionContract struct {
+	Underlying string     `json:"underlying"`
+	Type       OptionType `json:"type"`
+	Strike     float64    `json:"strike"`
+	Expiry     time.Time  `json:"expiry"`
+}
+
+type QuoteRequest struct {
+	ID             int            `json:"id"`
+	Option         OptionContract `json:"option"`
+	Side           OptionSide     `json:"side"`
+	Size           float64        `json:"size"`
+	Time           time.Time      `json:"time"`
+	RequestExpiry  time.Time      `json:"requestExpiry"`
+	Status         string         `json:"status"`
+	LimitPrice     *float64       `json:"limitPrice"`
+	HideLimitPrice bool           `json:"hideLimitPrice"`
+	Quotes         []OptionQuote  `json:"quotes"`
+}
+
+type OptionQuote struct {
+	ID          int            `json:"id"`
+	RequestID   int            `json:"requestId"`
+	Option      OptionContract `json:"option"`
+	Collateral  float64        `json:"collateral"`
+	Price       float64        `json:"price"`
+	Size        float64        `json:"size"`
+	QuoterSide  OptionSide     `json:"quoterSide"`
+	RequestSide OptionSide     `json:"requestSide"`
+	Status      string         `json:"status"`
+	QuoteExpiry *time.Time     `json:"quoteExpiry"`
+	Time        time.Time      `json:"time"`
+}
+
+// GetQuoteRequests FTX API docs: https://docs.ftx.com/#list-quote-requests
+func (s *OptionsService) GetQuoteRequests() ([]QuoteRequest, error) {
+	u := fmt.Sprintf(pathOptionsRequests, s.client.baseURL)
+
+	var out []QuoteRequest
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// GetMyQuoteRequests FTX API docs: https://docs.ftx.com/#your-quote-requests
+func (s *OptionsService) GetMyQuoteRequests() ([]QuoteRequest, error) {
+	u := fmt.Sprintf(pathOptionsMyRequests, s.client.baseURL)
+
+	var out []QuoteRequest
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type CreateQuoteRequestPayload struct {
+	Underlying     string     `json:"underlying"`
+	Type           OptionType `json:"type"`
+	Strike         float64    `json:"strike"`
+	Expiry         int64      `json:"expiry"`
+	Side           OptionSide `json:"side"`
+	Size           float64    `json:"size"`
+	LimitPrice     *float64   `json:"limitPrice,omitempty"`
+	HideLimitPrice bool       `json:"hideLimitPrice,omitempty"`
+	RequestExpiry  int64      `json:"requestExpiry,omitempty"`
+	CounterpartyID int        `json:"counterpartyId,omitempty"`
+}
+
+// CreateQuoteRequest FTX API docs: https://docs.ftx.com/#create-quote-request
+func (s *OptionsService) CreateQuoteRequest(in *CreateQuoteRequestPayload) (*QuoteRequest, error) {
+	u := fmt.Sprintf(pathOptionsRequests, s.client.baseURL)
+
+	var out QuoteRequest
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+// CancelQuoteRequest FTX API docs: https://docs.ftx.com/#cancel-quote-request
+func (s *OptionsService) CancelQuoteRequest(id int) (*QuoteRequest, error) {
+	u := fmt.Sprintf(pathOptionsRequest, s.client.baseURL, id)
+
+	var out QuoteRequest
+	err := s.client.DoPrivate(u, http.MethodDelete, nil, &out)
+	return &out, err
+}
+
+// GetQuotesForRequest FTX API docs: https://docs.ftx.com/#get-quotes-for-your-quote-request
+func (s *OptionsService) GetQuotesForRequest(requestID int) ([]OptionQuote, error) {
+	u := fmt.Sprintf(pathOptionsRequestQuotes, s.client.baseURL, requestID)
+
+	var out []OptionQuote
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type CreateQuotePayload struct {
+	Price float64 `json:"price"`
+}
+
+// CreateQuote FTX API docs: https://docs.ftx.com/#create-quote
+func (s *OptionsService) CreateQuote(requestID int, price float64) (*OptionQuote, error) {
+	u := fmt.Sprintf(pathOptionsRequestQuotes, s.client.baseURL, requestID)
+
+	in := CreateQuotePayload{Price: price}
+	var out OptionQuote
+	err := s.client.DoPrivate(u, http.MethodPost, &in, &out)
+	return &out, err
+}
+
+// GetMyQuotes FTX API docs: https://docs.ftx.com/#get-my-quotes
+func (s *OptionsService) GetMyQuotes() ([]OptionQuote, error) {
+	u := fmt.Sprintf(pathOptionsMyQuotes, s.client.baseURL)
+
+	var out []OptionQuote
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// CancelQuote FTX API docs: https://docs.ftx.com/#cancel-quote
+func (s *OptionsService) CancelQuote(id int) (*OptionQuote, error) {
+	u := fmt.Sprintf(pathOptionsQuote, s.client.baseURL, id)
+
+	var out OptionQuote
+	err := s.client.DoPrivate(u, http.MethodDelete, nil, &out)
+	return &out, err
+}
+
+// AcceptQuote FTX API docs: https://docs.ftx.com/#accept-options-quote
+func (s *OptionsService) AcceptQuote(id int) (*OptionQuote, error) {
+	u := fmt.Sprintf(pathOptionsQuoteAccept, s.client.baseURL, id)
+
+	var out OptionQuote
+	err := s.client.DoPrivate(u, http.MethodPost, nil, &out)
+	return &out, err
+}
+
+type OptionsAccountInfo struct {
+	USDBalance       float64 `json:"usdBalance"`
+	LiquidationPrice float64 `json:"liquidationPrice"`
+	Liquidating      bool    `json:"liquidating"`
+}
+
+// GetAccountInfo FTX API docs: https://docs.ftx.com/#get-account-options-info
+func (s *OptionsService) GetAccountInfo() (*OptionsAccountInfo, error) {
+	u := fmt.Sprintf(pathOptionsAccountInfo, s.client.baseURL)
+
+	var out OptionsAccountInfo
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return &out, err
+}
+
+type OptionPosition struct {
+	Option                OptionContract `json:"option"`
+	Side                  OptionSide     `json:"side"`
+	Size                  float64        `json:"size"`
+	NetSize               float64        `json:"netSize"`
+	EntryPrice            float64        `json:"entryPrice"`
+	PessimisticValuation  float64        `json:"pessimisticValuation"`
+	PessimisticIndexPrice float64        `json:"pessimisticIndexPrice"`
+	PessimisticVol        float64        `json:"pessimisticVol"`
+}
+
+// GetPositions FTX API docs: https://docs.ftx.com/#get-options-positions
+func (s *OptionsService) GetPositions() ([]OptionPosition, error) {
+	u := fmt.Sprintf(pathOptionsPositions, s.client.baseURL)
+
+	var out []OptionPosition
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type GetOptionsHistoryOptions struct {
+	Limit     int   `url:"limit,omitempty"`
+	StartTime int64 `url:"start_time,omitempty"`
+	EndTime   int64 `url:"end_time,omitempty"`
+}
+
+type OptionTrade struct {
+	ID     int            `json:"id"`
+	Option OptionContract `json:"option"`
+	Price  float64        `json:"price"`
+	Size   float64        `json:"size"`
+	Time   time.Time      `json:"time"`
+}
+
+// GetTrades FTX API docs: https://docs.ftx.com/#get-public-options-trades
+func (s *OptionsService) GetTrades(opts *GetOptionsHistoryOptions) ([]OptionTrade, error) {
+	u := fmt.Sprintf(pathOptionsTrades, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []OptionTrade
+	err = s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type OptionFill struct {
+	ID        int            `json:"id"`
+	QuoteID   int            `json:"quoteId"`
+	Option    OptionContract `json:"option"`
+	Side      OptionSide     `json:"side"`
+	Price     float64        `json:"price"`
+	Size      float64        `json:"size"`
+	Fee       float64        `json:"fee"`
+	FeeRate   float64        `json:"feeRate"`
+	Liquidity string         `json:"liquidity"`
+	Time      time.Time      `json:"time"`
+}
+
+// GetFills FTX API docs: https://docs.ftx.com/#get-options-fills
+func (s *OptionsService) GetFills(opts *GetOptionsHistoryOptions) ([]OptionFill, error) {
+	u := fmt.Sprintf(pathOptionsFills, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []OptionFill
+	err = s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type OptionsVolume struct {
+	Contracts       float64 `json:"contracts"`
+	UnderlyingTotal float64 `json:"underlying_total"`
+}
+
+// Get24hVolume FTX API docs: https://docs.ftx.com/#get-24h-option-volume
+func (s *OptionsService) Get24hVolume() (*OptionsVolume, error) {
+	u := fmt.Sprintf(pathOptions24hVolume, s.client.baseURL)
+
+	var out OptionsVolume
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return &out, err
+}
+
+type OptionsHistoricalVolume struct {
+	NumContracts float64   `json:"numContracts"`
+	StartTime    time.Time `json:"startTime"`
+	EndTime      time.Time `json:"endTime"`
+}
+
+// GetHistoricalVolumes FTX API docs: https://docs.ftx.com/#get-option-historical-volumes
+func (s *OptionsService) GetHistoricalVolumes(underlying string, opts *GetOptionsHistoryOptions) ([]OptionsHistoricalVolume, error) {
+	u := fmt.Sprintf(pathOptionsHistoricalVolume, s.client.baseURL, underlying)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []OptionsHistoricalVolume
+	err = s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type OptionsOpenInterest struct {
+	OpenInterest float64 `json:"openInterest"`
+}
+
+// GetOpenInterest FTX API docs: https://docs.ftx.com/#get-option-open-interest
+func (s *OptionsService) GetOpenInterest(underlying string) (*OptionsOpenInterest, error) {
+	u := fmt.Sprintf(pathOptionsOpenInterest, s.client.baseURL, underlying)
+
+	var out OptionsOpenInterest
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return &out, err
+}
@@ -0,0 +1,279 @@
+package ftx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+const testOptionContract = `{"underlying":"BTC","type":"call","strike":7800,"expiry":"2020-04-10T03:00:00+00:00"}`
+
+func TestOptionsService_GetQuoteRequests(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":512,"option":` + testOptionContract + `,"side":"sell","size":1.2,"time":"2020-04-07T21:57:09.346113+00:00","requestExpiry":"2020-04-07T22:02:09.346113+00:00"}]}`)
+	}
+
+	requests, err := c.Options.GetQuoteRequests()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 512, requests[0].ID)
+	assert.Equal(t, OptionTypeCall, requests[0].Option.Type)
+	assert.Equal(t, OptionSideSell, requests[0].Side)
+}
+
+func TestOptionsService_GetMyQuoteRequests(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":512,"option":` + testOptionContract + `,"status":"open","limitPrice":1.5,"quotes":[{"id":1,"price":1.4}]}]}`)
+	}
+
+	requests, err := c.Options.GetMyQuoteRequests()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, *requests[0].LimitPrice)
+	assert.Equal(t, 1.4, requests[0].Quotes[0].Price)
+}
+
+func TestOptionsService_CreateQuoteRequest(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":512,"option":` + testOptionContract + `,"status":"open"}}`)
+	}
+
+	request, err := c.Options.CreateQuoteRequest(&CreateQuoteRequestPayload{
+		Underlying: "BTC",
+		Type:       OptionTypeCall,
+		Strike:     7800,
+		Expiry:     1586487600,
+		Side:       OptionSideBuy,
+		Size:       1,
+	})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"underlying":"BTC","type":"call","strike":7800,"expiry":1586487600,"side":"buy","size":1}`, <-ch)
+	assert.Equal(t, 512, request.ID)
+}
+
+func TestOptionsService_CancelQuoteRequest(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Header.Method()) + " " + string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":{"id":512,"status":"cancelled"}}`)
+	}
+
+	request, err := c.Options.CancelQuoteRequest(512)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE /options/requests/512", <-ch)
+	assert.Equal(t, "cancelled", request.Status)
+}
+
+func TestOptionsService_GetQuotesForRequest(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"requestId":512,"collateral":445.1,"price":100,"quoteExpiry":null,"status":"open"}]}`)
+	}
+
+	quotes, err := c.Options.GetQuotesForRequest(512)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 512, quotes[0].RequestID)
+	assert.Nil(t, quotes[0].QuoteExpiry)
+}
+
+func TestOptionsService_CreateQuote(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 2)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":1,"requestId":512,"price":100,"quoterSide":"sell","status":"open"}}`)
+	}
+
+	quote, err := c.Options.CreateQuote(512, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/options/requests/512/quotes", <-ch)
+	assert.JSONEq(t, `{"price":100}`, <-ch)
+	assert.Equal(t, OptionSideSell, quote.QuoterSide)
+}
+
+func TestOptionsService_GetMyQuotes(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"option":` + testOptionContract + `,"requestSide":"buy"}]}`)
+	}
+
+	quotes, err := c.Options.GetMyQuotes()
+
+	assert.NoError(t, err)
+	assert.Equal(t, OptionSideBuy, quotes[0].RequestSide)
+	assert.Equal(t, float64(7800), quotes[0].Option.Strike)
+}
+
+func TestOptionsService_CancelQuote(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Header.Method()) + " " + string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":{"id":1,"status":"cancelled"}}`)
+	}
+
+	quote, err := c.Options.CancelQuote(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE /options/quotes/1", <-ch)
+	assert.Equal(t, "cancelled", quote.Status)
+}
+
+func TestOptionsService_AcceptQuote(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Header.Method()) + " " + string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":{"id":1,"status":"filled"}}`)
+	}
+
+	quote, err := c.Options.AcceptQuote(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "POST /options/quotes/1/accept", <-ch)
+	assert.Equal(t, "filled", quote.Status)
+}
+
+func TestOptionsService_GetAccountInfo(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":{"usdBalance":2320.2,"liquidationPrice":0.5,"liquidating":false}}`)
+	}
+
+	info, err := c.Options.GetAccountInfo()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2320.2, info.USDBalance)
+}
+
+func TestOptionsService_GetPositions(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"entryPrice":8923.1,"netSize":-1.2,"option":` + testOptionContract + `,"side":"sell","size":1.2}]}`)
+	}
+
+	positions, err := c.Options.GetPositions()
+
+	assert.NoError(t, err)
+	assert.Equal(t, -1.2, positions[0].NetSize)
+	assert.Equal(t, "BTC", positions[0].Option.Underlying)
+}
+
+func TestOptionsService_GetTrades(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.QueryArgs().QueryString())
+		ctx.SetBodyString(`{"success":true,"result":[{"id":9,"option":` + testOptionContract + `,"price":3.5,"size":1.2,"time":"2020-04-07T21:57:09.346113+00:00"}]}`)
+	}
+
+	trades, err := c.Options.GetTrades(&GetOptionsHistoryOptions{Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "limit=10", <-ch)
+	assert.Equal(t, 3.5, trades[0].Price)
+}
+
+func TestOptionsService_GetFills(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"quoteId":2,"option":` + testOptionContract + `,"side":"buy","fee":0.5,"liquidity":"taker"}]}`)
+	}
+
+	fills, err := c.Options.GetFills(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fills[0].QuoteID)
+	assert.Equal(t, OptionSideBuy, fills[0].Side)
+}
+
+func TestOptionsService_Get24hVolume(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":{"contracts":216.6,"underlying_total":3.8}}`)
+	}
+
+	volume, err := c.Options.Get24hVolume()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 216.6, volume.Contracts)
+	assert.Equal(t, 3.8, volume.UnderlyingTotal)
+}
+
+func TestOptionsService_GetHistoricalVolumes(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"numContracts":1.2,"startTime":"2020-04-07T00:00:00+00:00","endTime":"2020-04-08T00:00:00+00:00"}]}`)
+	}
+
+	volumes, err := c.Options.GetHistoricalVolumes("BTC", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.2, volumes[0].NumContracts)
+}
+
+func TestOptionsService_GetOpenInterest(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":{"openInterest":5.2}}`)
+	}
+
+	oi, err := c.Options.GetOpenInterest("BTC")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/options/open_interest/BTC", <-ch)
+	assert.Equal(t, 5.2, oi.OpenInterest)
+}
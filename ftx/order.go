@@ -0,0 +1,89 @@
+package ftx
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type OrderService service
+
+const (
+	pathOrders = "%s/orders"
+	pathOrder  = "%s/orders/%d"
+)
+
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+
+	OrderTypeLimit  = "limit"
+	OrderTypeMarket = "market"
+
+	OrderStatusNew    = "new"
+	OrderStatusOpen   = "open"
+	OrderStatusClosed = "closed"
+)
+
+type Order struct {
+	ID            int       `json:"id"`
+	ClientID      string    `json:"clientId"`
+	CreatedAt     time.Time `json:"createdAt"`
+	Future        string    `json:"future"`
+	Market        string    `json:"market"`
+	Type          string    `json:"type"`
+	Side          string    `json:"side"`
+	Price         float64   `json:"price"`
+	Size          float64   `json:"size"`
+	Status        string    `json:"status"`
+	FilledSize    float64   `json:"filledSize"`
+	RemainingSize float64   `json:"remainingSize"`
+	AvgFillPrice  float64   `json:"avgFillPrice"`
+	ReduceOnly    bool      `json:"reduceOnly"`
+	IOC           bool      `json:"ioc"`
+	PostOnly      bool      `json:"postOnly"`
+}
+
+type GetOpenOrdersOptions struct {
+	Market string `url:"market,omitempty"`
+}
+
+// GetOpenOrders FTX API docs: https://docs.ftx.com/#get-open-orders
+func (s *OrderService) GetOpenOrders(opts *GetOpenOrdersOptions) ([]Order, error) {
+	u := fmt.Sprintf(pathOrders, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Order
+	err = s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type PlaceOrderPayload struct {
+	Market     string   `json:"market"`
+	Side       string   `json:"side"`
+	Price      *float64 `json:"price"`
+	Type       string   `json:"type"`
+	Size       float64  `json:"size"`
+	ReduceOnly bool     `json:"reduceOnly,omitempty"`
+	IOC        bool     `json:"ioc,omitempty"`
+	PostOnly   bool     `json:"postOnly,omitempty"`
+	ClientID   string   `json:"clientId,omitempty"`
+}
+
+// PlaceOrder FTX API docs: https://docs.ftx.com/#place-order
+func (s *OrderService) PlaceOrder(in *PlaceOrderPayload) (*Order, error) {
+	u := fmt.Sprintf(pathOrders, s.client.baseURL)
+
+	var out Order
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+// Cancel FTX API docs: https://docs.ftx.com/#cancel-order
+func (s *OrderService) Cancel(id int) error {
+	u := fmt.Sprintf(pathOrder, s.client.baseURL, id)
+	return s.client.DoPrivate(u, http.MethodDelete, nil, nil)
+}
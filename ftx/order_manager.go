@@ -0,0 +1,213 @@
+package ftx
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+)
+
+// OrderManager keeps the open orders of an account from GetOpenOrders
+// snapshots and stream.Orders updates.
+//
+// Updates never move an order backwards: closed orders stay closed and the
+// filled size only grows, so a REST response that is older than the updates
+// already received cannot resurrect or roll back an order. Updates received
+// while a snapshot is in flight are replayed on top of it. Closed orders are
+// remembered for closedOrderTTL.
+type OrderManager struct {
+	orders *OrderService
+
+	syncMu sync.Mutex // serializes Sync
+
+	mu        sync.Mutex
+	open      map[int]*Order
+	closed    map[int]time.Time
+	lastPrune time.Time
+	syncing   bool
+	pending   []Order
+
+	now func() time.Time
+}
+
+// closedOrderTTL is how long closed orders are remembered to ignore late
+// updates, e.g. the response of PlaceOrder after the stream closed the order.
+const closedOrderTTL = time.Minute
+
+func NewOrderManager(orders *OrderService) *OrderManager {
+	return &OrderManager{orders: orders, open: make(map[int]*Order), closed: make(map[int]time.Time), now: time.Now}
+}
+
+// Sync replaces the open orders with a GetOpenOrders snapshot. Concurrent
+// calls are serialized.
+func (m *OrderManager) Sync() error {
+	m.syncMu.Lock()
+	defer m.syncMu.Unlock()
+
+	m.mu.Lock()
+	m.syncing = true
+	m.pending = nil
+	m.mu.Unlock()
+
+	snapshot, err := m.orders.GetOpenOrders(nil)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.syncing = false
+	pending := m.pending
+	m.pending = nil
+	if err != nil {
+		for _, o := range pending {
+			m.apply(o)
+		}
+		return err
+	}
+
+	m.open = make(map[int]*Order, len(snapshot))
+	for i := range snapshot {
+		if o := snapshot[i]; !m.isClosed(o.ID) {
+			m.open[o.ID] = &o
+		}
+	}
+	for _, o := range pending {
+		m.apply(o)
+	}
+	m.prune(m.now())
+	return nil
+}
+
+// Track records an order returned by PlaceOrder.
+func (m *OrderManager) Track(o *Order) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.syncing {
+		m.pending = append(m.pending, *o)
+	}
+	m.apply(*o)
+}
+
+// Feed applies a message returned by stream.Conn.Recv. Messages other than
+// stream.Orders are ignored.
+func (m *OrderManager) Feed(msg interface{}) {
+	v, ok := msg.(stream.Orders)
+	if !ok {
+		return
+	}
+
+	o := Order{
+		ID:            v.Data.ID,
+		ClientID:      v.Data.ClientID,
+		Market:        v.Data.Market,
+		Type:          v.Data.Type,
+		Side:          v.Data.Side,
+		Size:          v.Data.Size,
+		Price:         v.Data.Price,
+		ReduceOnly:    v.Data.ReduceOnly,
+		IOC:           v.Data.IOC,
+		PostOnly:      v.Data.PostOnly,
+		Status:        v.Data.Status,
+		FilledSize:    v.Data.FilledSize,
+		RemainingSize: v.Data.RemainingSize,
+		AvgFillPrice:  v.Data.AvgFillPrice,
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.syncing {
+		m.pending = append(m.pending, o)
+	}
+	m.apply(o)
+}
+
+func (m *OrderManager) isClosed(id int) bool {
+	_, ok := m.closed[id]
+	return ok
+}
+
+func (m *OrderManager) apply(o Order) {
+	if m.isClosed(o.ID) {
+		return
+	}
+	if o.Status == OrderStatusClosed {
+		delete(m.open, o.ID)
+		now := m.now()
+		m.closed[o.ID] = now
+		m.prune(now)
+		return
+	}
+
+	cur, ok := m.open[o.ID]
+	if !ok {
+		m.open[o.ID] = &o
+		return
+	}
+	if o.FilledSize < cur.FilledSize {
+		return
+	}
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = cur.CreatedAt
+	}
+	if o.Future == "" {
+		o.Future = cur.Future
+	}
+	*cur = o
+}
+
+// prune forgets the orders closed for longer than closedOrderTTL, at most once
+// per TTL. A snapshot in flight may have been requested before they closed,
+// so they are kept until it is applied.
+func (m *OrderManager) prune(now time.Time) {
+	if m.syncing || now.Sub(m.lastPrune) < closedOrderTTL {
+		return
+	}
+	m.lastPrune = now
+	for id, at := range m.closed {
+		if now.Sub(at) >= closedOrderTTL {
+			delete(m.closed, id)
+		}
+	}
+}
+
+// Get returns the open order with id.
+func (m *OrderManager) Get(id int) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	o, ok := m.open[id]
+	if !ok {
+		return Order{}, false
+	}
+	return *o, true
+}
+
+// GetByClientID returns the open order with clientID.
+func (m *OrderManager) GetByClientID(clientID string) (Order, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, o := range m.open {
+		if o.ClientID == clientID {
+			return *o, true
+		}
+	}
+	return Order{}, false
+}
+
+// Open returns the open orders of market, or of all markets if market is empty, ordered by ID.
+func (m *OrderManager) Open(market string) []Order {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]Order, 0, len(m.open))
+	for _, o := range m.open {
+		if market == "" || o.Market == market {
+			out = append(out, *o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -0,0 +1,170 @@
+package ftx
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func testOrderUpdate(id int, status string, filled float64) stream.Orders {
+	var v stream.Orders
+	v.Data.ID = id
+	v.Data.ClientID = "client-" + strconv.Itoa(id)
+	v.Data.Market = "BTC-PERP"
+	v.Data.Size = 1
+	v.Data.Status = status
+	v.Data.FilledSize = filled
+	v.Data.RemainingSize = 1 - filled
+	return v
+}
+
+func TestOrderManager(t *testing.T) {
+	c, _, teardown := setup()
+	defer teardown()
+
+	m := NewOrderManager(c.Orders)
+
+	m.Track(&Order{ID: 1, ClientID: "client-1", Market: "BTC-PERP", Size: 1, Status: OrderStatusNew})
+	m.Feed(testOrderUpdate(2, OrderStatusOpen, 0))
+	m.Feed(testOrderUpdate(1, OrderStatusOpen, 0.5))
+
+	o, ok := m.GetByClientID("client-1")
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, o.FilledSize)
+
+	m.Track(&Order{ID: 1, Market: "BTC-PERP", Size: 1, Status: OrderStatusNew})
+	o, _ = m.Get(1)
+	assert.Equal(t, 0.5, o.FilledSize, "stale update is ignored")
+
+	m.Feed(testOrderUpdate(1, OrderStatusClosed, 1))
+	m.Feed(testOrderUpdate(1, OrderStatusOpen, 0.5))
+
+	_, ok = m.Get(1)
+	assert.False(t, ok)
+	assert.Len(t, m.Open("BTC-PERP"), 1)
+	assert.Empty(t, m.Open("ETH-PERP"))
+}
+
+func TestOrderManager_Sync(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	m := NewOrderManager(c.Orders)
+	m.Feed(testOrderUpdate(5, OrderStatusOpen, 0))
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		// Updates arriving while the snapshot is in flight.
+		m.Feed(testOrderUpdate(1, OrderStatusClosed, 1))
+		m.Feed(testOrderUpdate(2, OrderStatusOpen, 0.7))
+		ctx.SetBodyString(`{"success":true,"result":[
+			{"id":1,"market":"BTC-PERP","status":"open","size":1,"filledSize":0.2},
+			{"id":2,"market":"BTC-PERP","status":"open","size":1,"filledSize":0.2},
+			{"id":3,"market":"ETH-PERP","status":"open","size":1}
+		]}`)
+	}
+
+	assert.NoError(t, m.Sync())
+
+	open := m.Open("")
+	assert.Len(t, open, 2)
+	assert.Equal(t, 2, open[0].ID)
+	assert.Equal(t, 0.7, open[0].FilledSize)
+	assert.Equal(t, 3, open[1].ID)
+}
+
+func TestOrderManager_TrackDuringSync(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	m := NewOrderManager(c.Orders)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		// PlaceOrder returning after the snapshot was taken.
+		m.Track(&Order{ID: 9, Market: "BTC-PERP", Size: 1, Status: OrderStatusNew})
+		ctx.SetBodyString(`{"success":true,"result":[{"id":3,"market":"ETH-PERP","status":"open","size":1}]}`)
+	}
+
+	assert.NoError(t, m.Sync())
+
+	open := m.Open("")
+	assert.Len(t, open, 2)
+	_, ok := m.Get(9)
+	assert.True(t, ok)
+}
+
+func TestOrderManager_PruneClosed(t *testing.T) {
+	c, _, teardown := setup()
+	defer teardown()
+
+	now := time.Unix(1600000000, 0)
+	m := NewOrderManager(c.Orders)
+	m.now = func() time.Time { return now }
+
+	m.Feed(testOrderUpdate(1, OrderStatusClosed, 1))
+	m.Feed(testOrderUpdate(1, OrderStatusOpen, 0.5))
+	_, ok := m.Get(1)
+	assert.False(t, ok, "late update of a closed order is ignored")
+
+	now = now.Add(closedOrderTTL)
+	m.Feed(testOrderUpdate(2, OrderStatusClosed, 1))
+	assert.Len(t, m.closed, 1)
+	assert.Contains(t, m.closed, 2)
+}
+
+func TestOrderManager_SyncKeepsClosed(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	m := NewOrderManager(c.Orders)
+	m.Feed(testOrderUpdate(7, OrderStatusClosed, 1))
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[]}`)
+	}
+	assert.NoError(t, m.Sync())
+
+	// The response of PlaceOrder arriving after the order closed.
+	m.Track(&Order{ID: 7, Market: "BTC-PERP", Size: 1, Status: OrderStatusNew})
+	assert.Empty(t, m.Open(""))
+}
+
+func TestOrderManager_ConcurrentSync(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	m := NewOrderManager(c.Orders)
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		ctx.SetBodyString(`{"success":true,"result":[{"id":1,"market":"BTC-PERP","status":"open","size":1}]}`)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, m.Sync())
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, maxInFlight)
+	assert.Len(t, m.Open(""), 1)
+}
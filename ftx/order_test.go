@@ -0,0 +1,69 @@
+package ftx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestOrderService_GetOpenOrders(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().QueryString())
+		ctx.SetBodyString(`{"success":true,"result":[{"id":9596912,"market":"XRP-PERP"}]}`)
+	}
+
+	orders, err := c.Orders.GetOpenOrders(&GetOpenOrdersOptions{Market: "XRP-PERP"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "market=XRP-PERP", <-ch)
+	assert.Equal(t, 9596912, orders[0].ID)
+}
+
+func TestOrderService_PlaceOrder(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"id":9596912,"status":"new"}}`)
+	}
+
+	price := 0.306525
+	order, err := c.Orders.PlaceOrder(&PlaceOrderPayload{
+		Market: "XRP-PERP",
+		Side:   SideSell,
+		Price:  &price,
+		Type:   OrderTypeLimit,
+		Size:   31431,
+	})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"market":"XRP-PERP","side":"sell","price":0.306525,"type":"limit","size":31431}`, <-ch)
+	assert.Equal(t, 9596912, order.ID)
+	assert.Equal(t, OrderStatusNew, order.Status)
+}
+
+func TestOrderService_Cancel(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Header.Method()) + " " + string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":"Order queued for cancellation"}`)
+	}
+
+	err := c.Orders.Cancel(9596912)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE /orders/9596912", <-ch)
+}
@@ -0,0 +1,772 @@
+package ftx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultPaperMakerFee = 0.0002
+	defaultPaperTakerFee = 0.0007
+
+	paperMaintenanceMargin = 0.03
+	paperEpsilon           = 1e-9
+)
+
+var ErrPaperClosed = errors.New("paper exchange closed")
+
+const defaultPaperEventBuffer = 1024
+
+type PaperOptions struct {
+	// Collateral is the USD balance.
+	Collateral float64
+	// Balances are the initial balances of other coins, e.g. to sell on spot markets.
+	Balances map[string]float64
+	MakerFee float64
+	TakerFee float64
+	Leverage int
+	// EventBuffer is the number of events kept for Recv, 1024 by default. When
+	// it is full the oldest event is dropped, see PaperExchange.Dropped.
+	EventBuffer int
+}
+
+// PaperExchange simulates an FTX account locally. Orders placed through a Client
+// created with WithPaperTrading are matched against the order books and trades
+// fed from MarketService or stream.Conn, and the resulting fills and order
+// updates are delivered by Recv as stream.Fills and stream.Orders.
+//
+// Fills of futures change the positions returned by GetPositions, fills of
+// spot markets the coin balances returned by Balances. Orders needing more
+// than the free collateral or coin balance are rejected.
+type PaperExchange struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	collateral float64
+	makerFee   float64
+	takerFee   float64
+	leverage   int
+
+	books     map[string]*paperBook
+	marks     map[string]float64
+	orders    map[int]*Order
+	positions map[string]*Position
+	balances  map[string]float64 // of coins other than USD, which is collateral
+
+	orderID int
+	fillID  int
+
+	events  []interface{} // ring buffer of events for Recv
+	head    int
+	pending int
+	dropped int
+	closed  bool
+
+	now func() time.Time
+}
+
+func NewPaperExchange(opts PaperOptions) *PaperExchange {
+	p := &PaperExchange{
+		collateral: opts.Collateral,
+		makerFee:   opts.MakerFee,
+		takerFee:   opts.TakerFee,
+		leverage:   opts.Leverage,
+		books:      make(map[string]*paperBook),
+		marks:      make(map[string]float64),
+		orders:     make(map[int]*Order),
+		positions:  make(map[string]*Position),
+		balances:   make(map[string]float64),
+		now:        time.Now,
+	}
+	for coin, v := range opts.Balances {
+		p.addBalance(coin, v)
+	}
+	if p.makerFee == 0 && p.takerFee == 0 {
+		p.makerFee, p.takerFee = defaultPaperMakerFee, defaultPaperTakerFee
+	}
+	if p.leverage == 0 {
+		p.leverage = Leverage10X
+	}
+	if opts.EventBuffer <= 0 {
+		opts.EventBuffer = defaultPaperEventBuffer
+	}
+	p.events = make([]interface{}, opts.EventBuffer)
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// UpdateOrderBook replaces the order book of market, e.g. with a snapshot from MarketService.GetOrderBook.
+func (p *PaperExchange) UpdateOrderBook(market string, ob *OrderBook) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	book := newPaperBook()
+	book.apply(ob.Bids, ob.Asks)
+	p.books[market] = book
+	p.matchBook(market)
+}
+
+// SyncOrderBook fetches the order book of market over REST and applies it.
+func (p *PaperExchange) SyncOrderBook(s *MarketService, market string, depth int) error {
+	ob, err := s.GetOrderBook(market, &GetOrderBookOptions{Depth: depth})
+	if err != nil {
+		return err
+	}
+	p.UpdateOrderBook(market, ob)
+	return nil
+}
+
+// Feed applies a message returned by stream.Conn.Recv. Messages other than
+// stream.OrderBook, stream.Trade and stream.Ticker are ignored.
+func (p *PaperExchange) Feed(msg interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch v := msg.(type) {
+	case stream.OrderBook:
+		book, ok := p.books[v.Market]
+		if !ok || v.Data.Action == "partial" {
+			book = newPaperBook()
+			p.books[v.Market] = book
+		}
+		book.apply(v.Data.Bids, v.Data.Asks)
+		p.matchBook(v.Market)
+	case stream.Trade:
+		for _, t := range v.Data {
+			p.marks[v.Market] = t.Price
+			p.matchTrade(v.Market, t.Price, t.Size)
+		}
+	case stream.Ticker:
+		if v.Data.Last > 0 {
+			p.marks[v.Market] = v.Data.Last
+		}
+	}
+}
+
+// Recv blocks until the next stream.Fills or stream.Orders event is available.
+func (p *PaperExchange) Recv() (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for p.pending == 0 && !p.closed {
+		p.cond.Wait()
+	}
+	if p.pending == 0 {
+		return nil, ErrPaperClosed
+	}
+	v := p.events[p.head]
+	p.events[p.head] = nil
+	p.head = (p.head + 1) % len(p.events)
+	p.pending--
+	return v, nil
+}
+
+// Dropped returns the number of events dropped because Recv did not keep up.
+func (p *PaperExchange) Dropped() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dropped
+}
+
+// Balances returns the balance of every coin traded or given in PaperOptions,
+// including USD.
+func (p *PaperExchange) Balances() map[string]float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	out := make(map[string]float64, len(p.balances)+1)
+	for coin, v := range p.balances {
+		out[coin] = v
+	}
+	out["USD"] = p.collateral
+	return out
+}
+
+// Close unblocks Recv once all pending events are consumed.
+func (p *PaperExchange) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	p.cond.Broadcast()
+	return nil
+}
+
+// serve answers req for uri, relative to the base URL, like the exchange
+// would, so that paper requests pass through the middleware of the client.
+func (p *PaperExchange) serve(uri string, req *fasthttp.Request, resp *fasthttp.Response) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	var in interface{}
+	if body := req.Body(); len(body) > 0 {
+		in = json.RawMessage(body)
+	}
+
+	p.mu.Lock()
+	result, err := p.route(string(req.Header.Method()), u, in)
+	p.mu.Unlock()
+
+	data := Response{Success: err == nil, Result: result}
+	resp.SetStatusCode(http.StatusOK)
+	if err != nil {
+		data.Error = err.Error()
+		resp.SetStatusCode(http.StatusBadRequest)
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	resp.Header.SetContentType("application/json")
+	resp.SetBody(b)
+	return nil
+}
+
+func (p *PaperExchange) route(method string, u *url.URL, in interface{}) (interface{}, error) {
+	path := strings.Trim(u.Path, "/")
+
+	switch {
+	case method == http.MethodGet && path == "account":
+		return p.account(), nil
+	case method == http.MethodGet && path == "positions":
+		return p.positionList(), nil
+	case method == http.MethodPost && path == "account/leverage":
+		var req RequestLeverage
+		if err := remarshal(in, &req); err != nil {
+			return nil, err
+		}
+		if req.Leverage <= 0 {
+			return nil, errors.New("invalid leverage")
+		}
+		p.leverage = req.Leverage
+		return nil, nil
+	case method == http.MethodGet && path == "orders":
+		return p.openOrders(u.Query().Get("market")), nil
+	case method == http.MethodPost && path == "orders":
+		var req PlaceOrderPayload
+		if err := remarshal(in, &req); err != nil {
+			return nil, err
+		}
+		return p.placeOrder(&req)
+	case method == http.MethodDelete && strings.HasPrefix(path, "orders/"):
+		id, err := strconv.Atoi(strings.TrimPrefix(path, "orders/"))
+		if err != nil {
+			return nil, errors.New("invalid order ID")
+		}
+		return p.cancelOrder(id)
+	}
+	return nil, fmt.Errorf("paper trading: %s /%s not supported", method, path)
+}
+
+func (p *PaperExchange) placeOrder(in *PlaceOrderPayload) (*Order, error) {
+	if in.Market == "" {
+		return nil, errors.New("missing parameter market")
+	}
+	if in.Side != SideBuy && in.Side != SideSell {
+		return nil, errors.New("invalid side")
+	}
+	if in.Size <= 0 {
+		return nil, errors.New("size must be positive")
+	}
+
+	p.orderID++
+	o := &Order{
+		ID:            p.orderID,
+		ClientID:      in.ClientID,
+		CreatedAt:     p.now(),
+		Market:        in.Market,
+		Type:          in.Type,
+		Side:          in.Side,
+		Size:          in.Size,
+		Status:        OrderStatusNew,
+		RemainingSize: in.Size,
+		ReduceOnly:    in.ReduceOnly,
+		IOC:           in.IOC,
+		PostOnly:      in.PostOnly,
+	}
+	if isFuture(in.Market) {
+		o.Future = in.Market
+	}
+
+	book := p.books[in.Market]
+	limit := math.Inf(1)
+	if in.Side == SideSell {
+		limit = 0
+	}
+
+	switch in.Type {
+	case OrderTypeMarket:
+		if book == nil || len(book.side(opposite(in.Side))) == 0 {
+			return nil, fmt.Errorf("no liquidity for market %s", in.Market)
+		}
+	case OrderTypeLimit:
+		if in.Price == nil || *in.Price <= 0 {
+			return nil, errors.New("missing parameter price")
+		}
+		o.Price = *in.Price
+		limit = o.Price
+	default:
+		return nil, errors.New("invalid type")
+	}
+
+	if in.ReduceOnly {
+		reducible := p.reducible(in.Market, in.Side)
+		if reducible <= paperEpsilon {
+			return nil, errors.New("reduce-only order would increase position")
+		}
+		o.Size = math.Min(o.Size, reducible)
+		o.RemainingSize = o.Size
+	}
+
+	price := o.Price
+	if o.Type == OrderTypeMarket {
+		price = book.reach(opposite(o.Side), o.Size)
+	}
+	if !p.affordable(o, price) {
+		return nil, errors.New("not enough balances")
+	}
+
+	p.emitOrder(o)
+
+	if o.Type == OrderTypeLimit && o.PostOnly && book != nil && book.crosses(o.Side, o.Price) {
+		o.Status = OrderStatusClosed
+		p.emitOrder(o)
+		v := *o
+		return &v, nil
+	}
+
+	p.take(o, limit, "taker")
+
+	switch {
+	case o.Status == OrderStatusClosed:
+	case o.Type == OrderTypeMarket || o.IOC:
+		o.Status = OrderStatusClosed
+		p.emitOrder(o)
+	default:
+		o.Status = OrderStatusOpen
+		p.orders[o.ID] = o
+		p.emitOrder(o)
+	}
+
+	v := *o
+	return &v, nil
+}
+
+// affordable reports whether the free collateral, for futures, or the free
+// balance of the coin spent, for spot markets, covers o executing at price.
+// Balances reserved by open orders are not free, the part of o reducing a
+// position needs no collateral.
+func (p *PaperExchange) affordable(o *Order, price float64) bool {
+	if isFuture(o.Market) {
+		increase := o.Size - p.reducible(o.Market, o.Side)
+		if increase <= paperEpsilon {
+			return true
+		}
+		need := increase*price/float64(p.leverage) + increase*price*p.takerFee
+		return need <= p.free("USD")+paperEpsilon
+	}
+
+	base, quote := splitSpot(o.Market)
+	if o.Side == SideSell {
+		return o.Size <= p.free(base)+paperEpsilon
+	}
+	return o.Size*price*(1+p.takerFee) <= p.free(quote)+paperEpsilon
+}
+
+// free returns the balance of coin not used by positions or open orders. The
+// free USD balance is the free collateral.
+func (p *PaperExchange) free(coin string) float64 {
+	v := p.balances[coin]
+	if coin == "USD" {
+		v = p.account().FreeCollateral
+	}
+	for _, o := range p.orders {
+		if isFuture(o.Market) {
+			if coin == "USD" && !o.ReduceOnly {
+				v -= o.RemainingSize * o.Price / float64(p.leverage)
+			}
+			continue
+		}
+		base, quote := splitSpot(o.Market)
+		switch {
+		case o.Side == SideSell && base == coin:
+			v -= o.RemainingSize
+		case o.Side == SideBuy && quote == coin:
+			v -= o.RemainingSize * o.Price
+		}
+	}
+	return v
+}
+
+func (p *PaperExchange) cancelOrder(id int) (string, error) {
+	o, ok := p.orders[id]
+	if !ok {
+		return "", errors.New("order already closed")
+	}
+	delete(p.orders, id)
+	o.Status = OrderStatusClosed
+	p.emitOrder(o)
+	return "Order queued for cancellation", nil
+}
+
+func (p *PaperExchange) openOrders(market string) []Order {
+	out := make([]Order, 0, len(p.orders))
+	for _, o := range p.sortedOrders(market) {
+		out = append(out, *o)
+	}
+	return out
+}
+
+func (p *PaperExchange) sortedOrders(market string) []*Order {
+	orders := make([]*Order, 0, len(p.orders))
+	for _, o := range p.orders {
+		if market == "" || o.Market == market {
+			orders = append(orders, o)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+// take fills o against the resting liquidity of the book up to limit. Taker
+// fills execute at the book price, maker fills at the order price.
+func (p *PaperExchange) take(o *Order, limit float64, liquidity string) {
+	book := p.books[o.Market]
+	if book == nil {
+		return
+	}
+
+	levels := book.side(opposite(o.Side))
+	for _, price := range book.sorted(opposite(o.Side)) {
+		if o.RemainingSize <= paperEpsilon {
+			break
+		}
+		if (o.Side == SideBuy && price > limit) || (o.Side == SideSell && price < limit) {
+			break
+		}
+		size := math.Min(o.RemainingSize, levels[price])
+		if levels[price] -= size; levels[price] <= paperEpsilon {
+			delete(levels, price)
+		}
+
+		fillPrice := price
+		if liquidity == "maker" {
+			fillPrice = o.Price
+		}
+		p.fill(o, fillPrice, size, liquidity)
+	}
+}
+
+func (p *PaperExchange) matchBook(market string) {
+	for _, o := range p.sortedOrders(market) {
+		p.take(o, o.Price, "maker")
+	}
+}
+
+// matchTrade fills resting orders when a trade prints at or through their price.
+func (p *PaperExchange) matchTrade(market string, price, size float64) {
+	for _, o := range p.sortedOrders(market) {
+		if size <= paperEpsilon {
+			return
+		}
+		if (o.Side == SideBuy && price > o.Price) || (o.Side == SideSell && price < o.Price) {
+			continue
+		}
+		n := math.Min(o.RemainingSize, size)
+		size -= n
+		p.fill(o, o.Price, n, "maker")
+	}
+}
+
+func (p *PaperExchange) fill(o *Order, price, size float64, liquidity string) {
+	feeRate := p.takerFee
+	if liquidity == "maker" {
+		feeRate = p.makerFee
+	}
+	fee := price * size * feeRate
+
+	if isFuture(o.Market) {
+		p.collateral -= fee
+		pos, ok := p.positions[o.Market]
+		if !ok {
+			pos = &Position{Future: o.Market}
+			p.positions[o.Market] = pos
+		}
+		p.collateral += applyFill(pos, o.Side, price, size)
+	} else {
+		// Spot fills exchange the base coin for the quote coin, fees are paid in the quote coin.
+		base, quote := splitSpot(o.Market)
+		signed := size
+		if o.Side == SideSell {
+			signed = -size
+		}
+		p.addBalance(base, signed)
+		p.addBalance(quote, -signed*price-fee)
+	}
+
+	o.AvgFillPrice = (o.AvgFillPrice*o.FilledSize + price*size) / (o.FilledSize + size)
+	o.FilledSize += size
+	o.RemainingSize -= size
+	if o.RemainingSize <= paperEpsilon {
+		o.RemainingSize = 0
+		o.Status = OrderStatusClosed
+		delete(p.orders, o.ID)
+	}
+
+	p.fillID++
+	v := stream.Fills{Type: "update", Channel: stream.ChannelFills}
+	v.Data.ID = p.fillID
+	v.Data.TradeID = p.fillID
+	v.Data.OrderID = o.ID
+	v.Data.Market = o.Market
+	v.Data.Future = o.Future
+	v.Data.Side = o.Side
+	v.Data.Price = price
+	v.Data.Size = size
+	v.Data.Fee = fee
+	v.Data.FeeRate = feeRate
+	v.Data.Liquidity = liquidity
+	v.Data.Time = p.now()
+	v.Data.Type = "order"
+	p.emit(v)
+	p.emitOrder(o)
+}
+
+func (p *PaperExchange) addBalance(coin string, v float64) {
+	if coin == "USD" {
+		p.collateral += v
+		return
+	}
+	p.balances[coin] += v
+}
+
+func (p *PaperExchange) reducible(market, side string) float64 {
+	pos, ok := p.positions[market]
+	if !ok {
+		return 0
+	}
+	if side == SideBuy && pos.NetSize < 0 {
+		return -pos.NetSize
+	}
+	if side == SideSell && pos.NetSize > 0 {
+		return pos.NetSize
+	}
+	return 0
+}
+
+func (p *PaperExchange) mark(market string) float64 {
+	if v, ok := p.marks[market]; ok {
+		return v
+	}
+	if book, ok := p.books[market]; ok {
+		bids, asks := book.sorted(SideBuy), book.sorted(SideSell)
+		if len(bids) > 0 && len(asks) > 0 {
+			return (bids[0] + asks[0]) / 2
+		}
+	}
+	return 0
+}
+
+func (p *PaperExchange) positionList() []Position {
+	names := make([]string, 0, len(p.positions))
+	for name := range p.positions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]Position, 0, len(names))
+	for _, name := range names {
+		pos := *p.positions[name]
+		for _, o := range p.sortedOrders(name) {
+			if o.Side == SideBuy {
+				pos.LongOrderSize += o.RemainingSize
+			} else {
+				pos.ShortOrderSize += o.RemainingSize
+			}
+		}
+
+		mark := p.mark(name)
+		if mark == 0 {
+			mark = pos.EntryPrice
+		}
+		markPosition(&pos, mark)
+		pos.OpenSize = math.Max(math.Abs(pos.NetSize+pos.LongOrderSize), math.Abs(pos.NetSize-pos.ShortOrderSize))
+		pos.InitialMarginRequirement = 1 / float64(p.leverage)
+		pos.MaintenanceMarginRequirement = paperMaintenanceMargin
+		pos.CollateralUsed = pos.Size * mark * pos.InitialMarginRequirement
+		out = append(out, pos)
+	}
+	return out
+}
+
+func (p *PaperExchange) account() *Account {
+	positions := p.positionList()
+
+	var unrealized, notional, used float64
+	for _, pos := range positions {
+		unrealized += pos.UnrealizedPnl
+		notional += math.Abs(pos.Cost + pos.UnrealizedPnl)
+		used += pos.CollateralUsed
+	}
+
+	total := p.collateral + unrealized
+	account := &Account{
+		Collateral:                   total,
+		FreeCollateral:               total - used,
+		InitialMarginRequirement:     1 / float64(p.leverage),
+		Leverage:                     float64(p.leverage),
+		MaintenanceMarginRequirement: paperMaintenanceMargin,
+		MakerFee:                     p.makerFee,
+		TakerFee:                     p.takerFee,
+		TotalAccountValue:            total,
+		TotalPositionSize:            notional,
+		Username:                     "paper",
+		Positions:                    positions,
+	}
+	if notional > 0 {
+		account.MarginFraction = total / notional
+		account.OpenMarginFraction = account.MarginFraction
+	}
+	return account
+}
+
+func (p *PaperExchange) emitOrder(o *Order) {
+	v := stream.Orders{Type: "update", Channel: stream.ChannelOrders}
+	v.Data.ID = o.ID
+	v.Data.ClientID = o.ClientID
+	v.Data.Market = o.Market
+	v.Data.Type = o.Type
+	v.Data.Side = o.Side
+	v.Data.Size = o.Size
+	v.Data.Price = o.Price
+	v.Data.ReduceOnly = o.ReduceOnly
+	v.Data.IOC = o.IOC
+	v.Data.PostOnly = o.PostOnly
+	v.Data.Status = o.Status
+	v.Data.FilledSize = o.FilledSize
+	v.Data.RemainingSize = o.RemainingSize
+	v.Data.AvgFillPrice = o.AvgFillPrice
+	p.emit(v)
+}
+
+// emit queues v for Recv, dropping the oldest event if the buffer is full.
+func (p *PaperExchange) emit(v interface{}) {
+	if p.pending == len(p.events) {
+		p.events[p.head] = nil
+		p.head = (p.head + 1) % len(p.events)
+		p.pending--
+		p.dropped++
+	}
+	p.events[(p.head+p.pending)%len(p.events)] = v
+	p.pending++
+	p.cond.Broadcast()
+}
+
+type paperBook struct {
+	bids map[float64]float64
+	asks map[float64]float64
+}
+
+func newPaperBook() *paperBook {
+	return &paperBook{bids: make(map[float64]float64), asks: make(map[float64]float64)}
+}
+
+// apply updates price levels, removing the levels with zero size.
+func (b *paperBook) apply(bids, asks [][]float64) {
+	for _, side := range []struct {
+		levels map[float64]float64
+		update [][]float64
+	}{{b.bids, bids}, {b.asks, asks}} {
+		for _, lv := range side.update {
+			if len(lv) < 2 {
+				continue
+			}
+			if lv[1] <= 0 {
+				delete(side.levels, lv[0])
+				continue
+			}
+			side.levels[lv[0]] = lv[1]
+		}
+	}
+}
+
+// side returns the bids for SideBuy and the asks for SideSell.
+func (b *paperBook) side(side string) map[float64]float64 {
+	if side == SideBuy {
+		return b.bids
+	}
+	return b.asks
+}
+
+// sorted returns the prices of one side of the book, best first.
+func (b *paperBook) sorted(side string) []float64 {
+	levels := b.side(side)
+	prices := make([]float64, 0, len(levels))
+	for price := range levels {
+		prices = append(prices, price)
+	}
+	if side == SideBuy {
+		sort.Sort(sort.Reverse(sort.Float64Slice(prices)))
+	} else {
+		sort.Float64s(prices)
+	}
+	return prices
+}
+
+// reach returns the price of the level of one side of the book at which size
+// would be filled, or of the last level if the book is not deep enough.
+func (b *paperBook) reach(side string, size float64) float64 {
+	var price float64
+	for _, price = range b.sorted(side) {
+		if size -= b.side(side)[price]; size <= paperEpsilon {
+			break
+		}
+	}
+	return price
+}
+
+func (b *paperBook) crosses(side string, price float64) bool {
+	best := b.sorted(opposite(side))
+	if len(best) == 0 {
+		return false
+	}
+	if side == SideBuy {
+		return best[0] <= price
+	}
+	return best[0] >= price
+}
+
+// splitSpot returns the base and quote coins of a spot market, e.g. BTC/USD.
+func splitSpot(market string) (base, quote string) {
+	i := strings.Index(market, "/")
+	return market[:i], market[i+1:]
+}
+
+func opposite(side string) string {
+	if side == SideBuy {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// remarshal copies v into out through its JSON representation, as the result of a live request would be decoded.
+func remarshal(v, out interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
@@ -0,0 +1,228 @@
+package ftx
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func paperSetup() (*Client, *PaperExchange) {
+	p := NewPaperExchange(PaperOptions{Collateral: 1000, MakerFee: 0.001, TakerFee: 0.002})
+	p.UpdateOrderBook("BTC-PERP", &OrderBook{
+		Bids: [][]float64{{99, 1}, {98, 2}},
+		Asks: [][]float64{{101, 1}, {102, 2}},
+	})
+	return New(WithPaperTrading(p)), p
+}
+
+func TestPaperExchange_MarketOrder(t *testing.T) {
+	c, p := paperSetup()
+
+	order, err := c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC-PERP", Side: SideBuy, Type: OrderTypeMarket, Size: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusClosed, order.Status)
+	assert.Equal(t, float64(2), order.FilledSize)
+	assert.Equal(t, 101.5, order.AvgFillPrice)
+
+	positions, err := c.Accounts.GetPositions()
+	assert.NoError(t, err)
+	assert.Equal(t, "BTC-PERP", positions[0].Future)
+	assert.Equal(t, float64(2), positions[0].NetSize)
+	assert.Equal(t, 101.5, positions[0].EntryPrice)
+
+	account, err := c.Accounts.GetInformation()
+	assert.NoError(t, err)
+	assert.InDelta(t, 1000-203*0.002, account.Collateral-positions[0].UnrealizedPnl, 1e-9)
+
+	events := []interface{}{}
+	_ = p.Close()
+	for {
+		v, err := p.Recv()
+		if err != nil {
+			assert.Equal(t, ErrPaperClosed, err)
+			break
+		}
+		events = append(events, v)
+	}
+	var fills int
+	for _, v := range events {
+		if f, ok := v.(stream.Fills); ok {
+			fills++
+			assert.Equal(t, "taker", f.Data.Liquidity)
+		}
+	}
+	assert.Equal(t, 2, fills)
+	assert.Equal(t, OrderStatusClosed, events[len(events)-1].(stream.Orders).Data.Status)
+}
+
+func TestPaperExchange_Spot(t *testing.T) {
+	c, p := paperSetup()
+	p.UpdateOrderBook("ETH/BTC", &OrderBook{
+		Bids: [][]float64{{0.07, 10}},
+		Asks: [][]float64{{0.08, 10}},
+	})
+	p.UpdateOrderBook("BTC/USD", &OrderBook{
+		Bids: [][]float64{{99, 10}},
+		Asks: [][]float64{{100, 10}},
+	})
+
+	_, err := c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC/USD", Side: SideBuy, Type: OrderTypeMarket, Size: 2})
+	assert.NoError(t, err)
+	_, err = c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "ETH/BTC", Side: SideBuy, Type: OrderTypeMarket, Size: 5})
+	assert.NoError(t, err)
+
+	balances := p.Balances()
+	assert.InDelta(t, 1000-200-200*0.002, balances["USD"], 1e-9)
+	assert.InDelta(t, 2-0.4-0.4*0.002, balances["BTC"], 1e-9)
+	assert.InDelta(t, 5, balances["ETH"], 1e-9)
+
+	// Spot fills are not positions.
+	positions, err := c.Accounts.GetPositions()
+	assert.NoError(t, err)
+	assert.Empty(t, positions)
+
+	account, err := c.Accounts.GetInformation()
+	assert.NoError(t, err)
+	assert.Zero(t, account.TotalPositionSize)
+	assert.InDelta(t, balances["USD"], account.Collateral, 1e-9)
+}
+
+func TestPaperExchange_NotEnoughBalances(t *testing.T) {
+	c, p := paperSetup()
+	p.UpdateOrderBook("BTC/USD", &OrderBook{
+		Bids: [][]float64{{99, 10}},
+		Asks: [][]float64{{100, 20}},
+	})
+	p.UpdateOrderBook("ETH/BTC", &OrderBook{
+		Bids: [][]float64{{0.07, 10}},
+		Asks: [][]float64{{0.08, 10}},
+	})
+
+	// Spot buys spend USD, spot sells the coin.
+	_, err := c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC/USD", Side: SideBuy, Type: OrderTypeMarket, Size: 10})
+	assert.EqualError(t, err, "not enough balances")
+	_, err = c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "ETH/BTC", Side: SideSell, Type: OrderTypeMarket, Size: 1})
+	assert.EqualError(t, err, "not enough balances")
+
+	// Open orders reserve their balance.
+	price := 90.0
+	_, err = c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC/USD", Side: SideBuy, Price: &price, Type: OrderTypeLimit, Size: 10})
+	assert.NoError(t, err)
+	_, err = c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC/USD", Side: SideBuy, Price: &price, Type: OrderTypeLimit, Size: 2})
+	assert.EqualError(t, err, "not enough balances")
+
+	// Futures need the initial margin in free collateral, 100 USD are left.
+	_, err = c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC-PERP", Side: SideBuy, Type: OrderTypeMarket, Size: 10})
+	assert.EqualError(t, err, "not enough balances")
+
+	assert.Equal(t, map[string]float64{"USD": 1000}, p.Balances())
+	positions, err := c.Accounts.GetPositions()
+	assert.NoError(t, err)
+	assert.Empty(t, positions)
+}
+
+func TestPaperExchange_LimitOrder(t *testing.T) {
+	c, p := paperSetup()
+
+	price := 100.0
+	order, err := c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC-PERP", Side: SideSell, Price: &price, Type: OrderTypeLimit, Size: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusOpen, order.Status)
+
+	orders, err := c.Orders.GetOpenOrders(&GetOpenOrdersOptions{Market: "BTC-PERP"})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+
+	var trade stream.Trade
+	_ = json.Unmarshal([]byte(`{"market":"BTC-PERP","data":[{"price":100.5,"size":0.4}]}`), &trade)
+	p.Feed(trade)
+
+	positions, err := c.Accounts.GetPositions()
+	assert.NoError(t, err)
+	assert.Equal(t, -0.4, positions[0].NetSize)
+	assert.Equal(t, 0.6, positions[0].ShortOrderSize)
+	assert.InDelta(t, -0.2, positions[0].UnrealizedPnl, 1e-9)
+
+	reduce := 90.0
+	_, err = c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC-PERP", Side: SideBuy, Price: &reduce, Type: OrderTypeLimit, Size: 1, ReduceOnly: true})
+	assert.NoError(t, err)
+
+	p.UpdateOrderBook("BTC-PERP", &OrderBook{Asks: [][]float64{{89, 5}}})
+
+	positions, err = c.Accounts.GetPositions()
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), positions[0].NetSize)
+	assert.InDelta(t, 4, positions[0].RealizedPnl, 1e-9)
+}
+
+func TestPaperExchange_Cancel(t *testing.T) {
+	c, _ := paperSetup()
+
+	price := 90.0
+	order, err := c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC-PERP", Side: SideBuy, Price: &price, Type: OrderTypeLimit, Size: 1})
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.Orders.Cancel(order.ID))
+	assert.EqualError(t, c.Orders.Cancel(order.ID), "order already closed")
+
+	orders, err := c.Orders.GetOpenOrders(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func TestPaperExchange_PostOnly(t *testing.T) {
+	c, _ := paperSetup()
+
+	price := 101.0
+	order, err := c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC-PERP", Side: SideBuy, Price: &price, Type: OrderTypeLimit, Size: 1, PostOnly: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, OrderStatusClosed, order.Status)
+	assert.Equal(t, float64(0), order.FilledSize)
+}
+
+func TestPaperExchange_EventBuffer(t *testing.T) {
+	p := NewPaperExchange(PaperOptions{Collateral: 1000, EventBuffer: 2})
+	p.UpdateOrderBook("BTC-PERP", &OrderBook{Asks: [][]float64{{101, 10}}})
+	c := New(WithPaperTrading(p))
+
+	for i := 0; i < 3; i++ {
+		_, err := c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC-PERP", Side: SideBuy, Type: OrderTypeMarket, Size: 1})
+		assert.NoError(t, err)
+	}
+
+	// Each order emits an update when it is placed, a fill and an update when
+	// it is closed. Only the last two are kept.
+	assert.Equal(t, 7, p.Dropped())
+	v, err := p.Recv()
+	assert.NoError(t, err)
+	assert.IsType(t, stream.Fills{}, v)
+	v, err = p.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, v.(stream.Orders).Data.ID)
+}
+
+func TestPaperExchange_Middleware(t *testing.T) {
+	p := NewPaperExchange(PaperOptions{Collateral: 1000})
+
+	var paths []string
+	c := New(WithPaperTrading(p), WithMiddleware(func(next DoFunc) DoFunc {
+		return func(req *fasthttp.Request, resp *fasthttp.Response) error {
+			err := next(req, resp)
+			paths = append(paths, string(req.URI().Path())+" "+strconv.Itoa(resp.StatusCode()))
+			return err
+		}
+	}))
+
+	_, err := c.Accounts.GetInformation()
+	assert.NoError(t, err)
+	_, err = c.Orders.PlaceOrder(&PlaceOrderPayload{Market: "BTC-PERP", Side: SideBuy, Type: OrderTypeMarket, Size: 1})
+	assert.EqualError(t, err, "no liquidity for market BTC-PERP")
+
+	assert.Equal(t, []string{"/api/account 200", "/api/orders 400"}, paths)
+}
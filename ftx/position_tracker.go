@@ -0,0 +1,329 @@
+package ftx
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+)
+
+const positionEpsilon = 1e-9
+
+// applyFill updates pos with a fill and returns the realized PnL.
+func applyFill(pos *Position, side string, price, size float64) float64 {
+	signed := size
+	if side == SideSell {
+		signed = -size
+	}
+	if pos.NetSize == 0 || (pos.NetSize > 0) == (signed > 0) {
+		pos.EntryPrice = (math.Abs(pos.NetSize)*pos.EntryPrice + size*price) / (math.Abs(pos.NetSize) + size)
+		pos.NetSize += signed
+		return 0
+	}
+
+	closing := math.Min(math.Abs(pos.NetSize), size)
+	pnl := closing * (price - pos.EntryPrice)
+	if pos.NetSize < 0 {
+		pnl = -pnl
+	}
+	pos.RealizedPnl += pnl
+
+	pos.NetSize += signed
+	switch {
+	case math.Abs(pos.NetSize) <= positionEpsilon:
+		pos.NetSize, pos.EntryPrice = 0, 0
+	case (pos.NetSize > 0) == (signed > 0):
+		pos.EntryPrice = price
+	}
+	return pnl
+}
+
+// markPosition sets the fields of pos derived from its net size and the mark price.
+func markPosition(pos *Position, mark float64) {
+	pos.Size = math.Abs(pos.NetSize)
+	pos.Side = SideBuy
+	if pos.NetSize < 0 {
+		pos.Side = SideSell
+	}
+	pos.Cost = pos.NetSize * pos.EntryPrice
+	pos.UnrealizedPnl = pos.NetSize * (mark - pos.EntryPrice)
+}
+
+// PositionDrift is a difference between a tracked position and the exchange.
+type PositionDrift struct {
+	Future  string
+	Local   float64
+	Remote  float64
+	Tracked Position
+}
+
+// PositionTracker maintains per-market positions, average entry prices and
+// PnL from stream.Fills and stream.Ticker messages. Realized PnL is net of fees.
+type PositionTracker struct {
+	accounts *AccountService
+
+	mu        sync.Mutex
+	positions map[string]*Position
+	marks     map[string]float64
+	fees      map[string]float64
+	covered   map[string]*cover
+}
+
+// cover describes the snapshot a position was last replaced with, in order to
+// skip the fills it includes.
+type cover struct {
+	sent, received time.Time // exchange times around the request
+	before         float64   // tracked net size when the request was sent
+	seen           float64   // net size of the fills within the request since it was sent
+	netSize        float64   // of the snapshot
+}
+
+// snapshot is the result of GetPositions and the state of the tracker when it was requested.
+type snapshot struct {
+	remote         []Position
+	sent, received time.Time
+	before         map[string]float64
+}
+
+func NewPositionTracker(accounts *AccountService) *PositionTracker {
+	return &PositionTracker{
+		accounts:  accounts,
+		positions: make(map[string]*Position),
+		marks:     make(map[string]float64),
+		fees:      make(map[string]float64),
+		covered:   make(map[string]*cover),
+	}
+}
+
+// snapshot requests the futures positions from GetPositions.
+func (t *PositionTracker) snapshot() (*snapshot, error) {
+	t.mu.Lock()
+	before := make(map[string]float64, len(t.positions))
+	for name, pos := range t.positions {
+		before[name] = pos.NetSize
+	}
+	t.mu.Unlock()
+
+	sent := time.Now().Add(t.accounts.client.ClockSkew())
+	remote, err := t.accounts.GetPositions()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{remote: remote, sent: sent, received: time.Now().Add(t.accounts.client.ClockSkew()), before: before}, nil
+}
+
+// replace replaces the position of name with pos from snap. t.mu must be held.
+func (t *PositionTracker) replace(name string, pos *Position, snap *snapshot) {
+	c := &cover{sent: snap.sent, received: snap.received, before: snap.before[name]}
+	if old, ok := t.positions[name]; ok {
+		c.seen = old.NetSize - c.before
+	}
+	if pos != nil {
+		c.netSize = pos.NetSize
+		t.positions[name] = pos
+	} else {
+		delete(t.positions, name)
+	}
+	t.covered[name] = c
+}
+
+// Seed replaces the tracked futures positions with the ones from GetPositions.
+// Fills received afterwards are skipped if the positions include them, see
+// ApplyFill.
+func (t *PositionTracker) Seed() error {
+	snap, err := t.snapshot()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	remote := make(map[string]bool, len(snap.remote))
+	for i := range snap.remote {
+		pos := snap.remote[i]
+		remote[pos.Future] = true
+		t.replace(pos.Future, &pos, snap)
+	}
+	for name := range t.positions {
+		if isFuture(name) && !remote[name] {
+			t.replace(name, nil, snap)
+		}
+	}
+	return nil
+}
+
+// Feed applies a message returned by stream.Conn.Recv. Messages other than
+// stream.Fills and stream.Ticker are ignored.
+func (t *PositionTracker) Feed(msg interface{}) {
+	switch v := msg.(type) {
+	case stream.Fills:
+		t.ApplyFill(v)
+	case stream.Ticker:
+		t.mu.Lock()
+		if v.Data.Last > 0 {
+			t.marks[v.Market] = v.Data.Last
+		}
+		t.mu.Unlock()
+	}
+}
+
+// ApplyFill applies a fill, unless the snapshot its position was last replaced
+// with by Seed or Reconcile includes it. Fills before the request are included.
+// Fills while the request was in flight may be, so they are skipped as long as
+// the snapshot equals the tracked position before the request plus the fills
+// received since. A fill whose time is unknown is always applied.
+func (t *PositionTracker) ApplyFill(f stream.Fills) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	name := f.Data.Market
+	if f.Data.Future != "" {
+		name = f.Data.Future
+	}
+	if c, ok := t.covered[name]; ok && !f.Data.Time.IsZero() && !f.Data.Time.After(c.received) {
+		if !f.Data.Time.After(c.sent) {
+			return
+		}
+		if f.Data.Side == SideBuy {
+			c.seen += f.Data.Size
+		} else {
+			c.seen -= f.Data.Size
+		}
+		if math.Abs(c.before+c.seen-c.netSize) <= positionEpsilon {
+			return
+		}
+	}
+	pos, ok := t.positions[name]
+	if !ok {
+		pos = &Position{Future: name}
+		t.positions[name] = pos
+	}
+	applyFill(pos, f.Data.Side, f.Data.Price, f.Data.Size)
+	pos.RealizedPnl -= f.Data.Fee
+	t.fees[name] += f.Data.Fee
+	t.marks[name] = f.Data.Price
+}
+
+// Position returns the tracked position of market, marked to the last ticker or fill price.
+func (t *PositionTracker) Position(market string) (Position, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pos, ok := t.positions[market]
+	if !ok {
+		return Position{}, false
+	}
+	return t.marked(pos), true
+}
+
+func (t *PositionTracker) Positions() []Position {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	names := make([]string, 0, len(t.positions))
+	for name := range t.positions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]Position, 0, len(names))
+	for _, name := range names {
+		out = append(out, t.marked(t.positions[name]))
+	}
+	return out
+}
+
+// Fees returns the fees paid on market since the tracker was created.
+func (t *PositionTracker) Fees(market string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.fees[market]
+}
+
+func (t *PositionTracker) marked(pos *Position) Position {
+	v := *pos
+	mark, ok := t.marks[v.Future]
+	if !ok {
+		return v
+	}
+	markPosition(&v, mark)
+	return v
+}
+
+// Reconcile compares the tracked futures positions with GetPositions and
+// returns the ones whose net size differs. Drifted positions are replaced
+// with the exchange state, keeping the tracked realized PnL. Fills of them
+// received afterwards are skipped if the exchange state includes them, see
+// ApplyFill.
+func (t *PositionTracker) Reconcile() ([]PositionDrift, error) {
+	snap, err := t.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	byName := make(map[string]Position, len(snap.remote))
+	for _, pos := range snap.remote {
+		byName[pos.Future] = pos
+	}
+	for name, pos := range t.positions {
+		if _, ok := byName[name]; !ok && isFuture(name) {
+			byName[name] = Position{Future: name, RealizedPnl: pos.RealizedPnl}
+		}
+	}
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var drifts []PositionDrift
+	for _, name := range names {
+		r := byName[name]
+		local, ok := t.positions[name]
+		if !ok {
+			local = &Position{Future: name}
+		}
+		if math.Abs(local.NetSize-r.NetSize) <= positionEpsilon {
+			continue
+		}
+		drifts = append(drifts, PositionDrift{Future: name, Local: local.NetSize, Remote: r.NetSize, Tracked: *local})
+		r.RealizedPnl = local.RealizedPnl
+		t.replace(name, &r, snap)
+	}
+	return drifts, nil
+}
+
+// ReconcileRegular calls Reconcile every interval until ctx is done and
+// reports drifts and errors to f.
+func (t *PositionTracker) ReconcileRegular(ctx context.Context, interval time.Duration, f func([]PositionDrift, error)) {
+	go func() {
+		tk := time.NewTicker(interval)
+		defer tk.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tk.C:
+				drifts, err := t.Reconcile()
+				if len(drifts) > 0 || err != nil {
+					f(drifts, err)
+				}
+			}
+		}
+	}()
+}
+
+// isFuture reports whether market is a futures market, which GetPositions covers.
+func isFuture(market string) bool {
+	return !strings.Contains(market, "/")
+}
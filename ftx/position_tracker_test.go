@@ -0,0 +1,140 @@
+package ftx
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func testFill(market, side string, price, size, fee float64) stream.Fills {
+	var f stream.Fills
+	f.Data.Future = market
+	f.Data.Market = market
+	f.Data.Side = side
+	f.Data.Price = price
+	f.Data.Size = size
+	f.Data.Fee = fee
+	f.Data.Time = time.Now()
+	return f
+}
+
+func TestPositionTracker(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"future":"ETH-PERP","netSize":2,"entryPrice":2000}]}`)
+	}
+
+	tracker := NewPositionTracker(c.Accounts)
+	assert.NoError(t, tracker.Seed())
+
+	tracker.Feed(testFill("ETH-PERP", SideBuy, 2300, 1, 0.5))
+	tracker.Feed(testFill("ETH-PERP", SideSell, 2500, 2, 1))
+
+	var ticker stream.Ticker
+	_ = json.Unmarshal([]byte(`{"market":"ETH-PERP","data":{"last":2600}}`), &ticker)
+	tracker.Feed(ticker)
+
+	pos, ok := tracker.Position("ETH-PERP")
+	assert.True(t, ok)
+	assert.Equal(t, float64(1), pos.NetSize)
+	assert.Equal(t, float64(2100), pos.EntryPrice)
+	assert.Equal(t, float64(800-1.5), pos.RealizedPnl)
+	assert.Equal(t, float64(500), pos.UnrealizedPnl)
+	assert.Equal(t, 1.5, tracker.Fees("ETH-PERP"))
+
+	tracker.Feed(testFill("ETH-PERP", SideSell, 2600, 3, 0))
+
+	pos, _ = tracker.Position("ETH-PERP")
+	assert.Equal(t, float64(-2), pos.NetSize)
+	assert.Equal(t, float64(2600), pos.EntryPrice)
+	assert.Equal(t, SideSell, pos.Side)
+
+	tracker.Feed(testFill("BTC/USD", SideBuy, 50000, 0.1, 0))
+	assert.Len(t, tracker.Positions(), 2)
+}
+
+func TestPositionTracker_Reconcile(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"future":"ETH-PERP","netSize":1,"entryPrice":2000}]}`)
+	}
+
+	tracker := NewPositionTracker(c.Accounts)
+	tracker.Feed(testFill("ETH-PERP", SideBuy, 2000, 1, 0))
+	tracker.Feed(testFill("BTC-PERP", SideBuy, 50000, 0.1, 0))
+	tracker.Feed(testFill("BTC/USD", SideBuy, 50000, 0.1, 0))
+
+	drifts, err := tracker.Reconcile()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PositionDrift{{Future: "BTC-PERP", Local: 0.1, Remote: 0, Tracked: Position{Future: "BTC-PERP", NetSize: 0.1, EntryPrice: 50000}}}, drifts)
+
+	pos, _ := tracker.Position("BTC-PERP")
+	assert.Equal(t, float64(0), pos.NetSize)
+
+	drifts, err = tracker.Reconcile()
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+}
+
+func TestPositionTracker_ReconcileInFlightFill(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"future":"ETH-PERP","netSize":2,"entryPrice":2000}]}`)
+	}
+
+	tracker := NewPositionTracker(c.Accounts)
+	tracker.Feed(testFill("ETH-PERP", SideBuy, 2000, 1, 0))
+	// The second fill is included in the snapshot but not received yet.
+	inFlight := testFill("ETH-PERP", SideBuy, 2000, 1, 0)
+
+	drifts, err := tracker.Reconcile()
+	assert.NoError(t, err)
+	assert.Len(t, drifts, 1)
+
+	tracker.Feed(inFlight)
+	pos, _ := tracker.Position("ETH-PERP")
+	assert.Equal(t, float64(2), pos.NetSize)
+
+	tracker.Feed(testFill("ETH-PERP", SideBuy, 2000, 1, 0))
+	pos, _ = tracker.Position("ETH-PERP")
+	assert.Equal(t, float64(3), pos.NetSize)
+}
+
+func TestPositionTracker_ReconcileFillDuringRequest(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	tracker := NewPositionTracker(c.Accounts)
+	tracker.Feed(testFill("ETH-PERP", SideBuy, 2000, 1, 0))
+
+	// Both fills happen while the request is in flight, the snapshot
+	// includes the first only.
+	var included, excluded stream.Fills
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		included = testFill("ETH-PERP", SideBuy, 2000, 1, 0)
+		excluded = testFill("ETH-PERP", SideBuy, 2000, 0.5, 0)
+		ctx.SetBodyString(`{"success":true,"result":[{"future":"ETH-PERP","netSize":2,"entryPrice":2000}]}`)
+	}
+
+	_, err := tracker.Reconcile()
+	assert.NoError(t, err)
+
+	tracker.Feed(included)
+	pos, _ := tracker.Position("ETH-PERP")
+	assert.Equal(t, float64(2), pos.NetSize)
+
+	tracker.Feed(excluded)
+	pos, _ = tracker.Position("ETH-PERP")
+	assert.Equal(t, 2.5, pos.NetSize)
+}
@@ -0,0 +1,168 @@
+package ftx
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type SpotMarginService service
+
+const (
+	pathSpotMarginBorrowRates    = "%s/spot_margin/borrow_rates"
+	pathSpotMarginLendingRates   = "%s/spot_margin/lending_rates"
+	pathSpotMarginBorrowSummary  = "%s/spot_margin/borrow_summary"
+	pathSpotMarginMarketInfo     = "%s/spot_margin/market_info"
+	pathSpotMarginBorrowHistory  = "%s/spot_margin/borrow_history"
+	pathSpotMarginLendingHistory = "%s/spot_margin/lending_history"
+	pathSpotMarginOffers         = "%s/spot_margin/offers"
+	pathSpotMarginLendingInfo    = "%s/spot_margin/lending_info"
+)
+
+type SpotMarginRate struct {
+	Coin     string  `json:"coin"`
+	Estimate float64 `json:"estimate"`
+	Previous float64 `json:"previous"`
+}
+
+// GetBorrowRates FTX API docs: https://docs.ftx.com/#get-borrow-rates
+func (s *SpotMarginService) GetBorrowRates() ([]SpotMarginRate, error) {
+	u := fmt.Sprintf(pathSpotMarginBorrowRates, s.client.baseURL)
+
+	var out []SpotMarginRate
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// GetLendingRates FTX API docs: https://docs.ftx.com/#get-lending-rates
+func (s *SpotMarginService) GetLendingRates() ([]SpotMarginRate, error) {
+	u := fmt.Sprintf(pathSpotMarginLendingRates, s.client.baseURL)
+
+	var out []SpotMarginRate
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type BorrowSummary struct {
+	Coin string  `json:"coin"`
+	Size float64 `json:"size"`
+}
+
+// GetDailyBorrowedAmounts FTX API docs: https://docs.ftx.com/#get-daily-borrowed-amounts
+func (s *SpotMarginService) GetDailyBorrowedAmounts() ([]BorrowSummary, error) {
+	u := fmt.Sprintf(pathSpotMarginBorrowSummary, s.client.baseURL)
+
+	var out []BorrowSummary
+	err := s.client.DoPublic(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type SpotMarginMarketInfo struct {
+	Coin          string  `json:"coin"`
+	Borrowed      float64 `json:"borrowed"`
+	Free          float64 `json:"free"`
+	EstimatedRate float64 `json:"estimatedRate"`
+	PreviousRate  float64 `json:"previousRate"`
+}
+
+type GetMarketInfoOptions struct {
+	Market string `url:"market"`
+}
+
+// GetMarketInfo FTX API docs: https://docs.ftx.com/#get-market-info
+func (s *SpotMarginService) GetMarketInfo(market string) ([]SpotMarginMarketInfo, error) {
+	u := fmt.Sprintf(pathSpotMarginMarketInfo, s.client.baseURL)
+	u, err := addOptions(u, &GetMarketInfoOptions{Market: market})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []SpotMarginMarketInfo
+	err = s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type GetSpotMarginHistoryOptions struct {
+	StartTime int64 `url:"start_time,omitempty"`
+	EndTime   int64 `url:"end_time,omitempty"`
+}
+
+type BorrowHistory struct {
+	Coin string    `json:"coin"`
+	Cost float64   `json:"cost"`
+	Rate float64   `json:"rate"`
+	Size float64   `json:"size"`
+	Time time.Time `json:"time"`
+}
+
+// GetBorrowHistory FTX API docs: https://docs.ftx.com/#get-my-borrow-history
+func (s *SpotMarginService) GetBorrowHistory(opts *GetSpotMarginHistoryOptions) ([]BorrowHistory, error) {
+	u := fmt.Sprintf(pathSpotMarginBorrowHistory, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []BorrowHistory
+	err = s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type LendingHistory struct {
+	Coin     string    `json:"coin"`
+	Proceeds float64   `json:"proceeds"`
+	Rate     float64   `json:"rate"`
+	Size     float64   `json:"size"`
+	Time     time.Time `json:"time"`
+}
+
+// GetLendingHistory FTX API docs: https://docs.ftx.com/#get-my-lending-history
+func (s *SpotMarginService) GetLendingHistory(opts *GetSpotMarginHistoryOptions) ([]LendingHistory, error) {
+	u := fmt.Sprintf(pathSpotMarginLendingHistory, s.client.baseURL)
+	u, err := addOptions(u, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []LendingHistory
+	err = s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type LendingOffer struct {
+	Coin string  `json:"coin"`
+	Rate float64 `json:"rate"`
+	Size float64 `json:"size"`
+}
+
+// GetLendingOffers FTX API docs: https://docs.ftx.com/#get-lending-offers
+func (s *SpotMarginService) GetLendingOffers() ([]LendingOffer, error) {
+	u := fmt.Sprintf(pathSpotMarginOffers, s.client.baseURL)
+
+	var out []LendingOffer
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type LendingInfo struct {
+	Coin     string  `json:"coin"`
+	Lendable float64 `json:"lendable"`
+	Locked   float64 `json:"locked"`
+	MinRate  float64 `json:"minRate"`
+	Offered  float64 `json:"offered"`
+}
+
+// GetLendingInfo FTX API docs: https://docs.ftx.com/#get-lending-info
+func (s *SpotMarginService) GetLendingInfo() ([]LendingInfo, error) {
+	u := fmt.Sprintf(pathSpotMarginLendingInfo, s.client.baseURL)
+
+	var out []LendingInfo
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// SubmitLendingOffer FTX API docs: https://docs.ftx.com/#submit-lending-offer
+func (s *SpotMarginService) SubmitLendingOffer(in *LendingOffer) error {
+	u := fmt.Sprintf(pathSpotMarginOffers, s.client.baseURL)
+	return s.client.DoPrivate(u, http.MethodPost, in, nil)
+}
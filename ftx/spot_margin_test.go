@@ -0,0 +1,147 @@
+package ftx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSpotMarginService_GetBorrowRates(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"BTC","estimate":1.45e-06,"previous":1.44e-06}]}`)
+	}
+
+	rates, err := c.SpotMargin.GetBorrowRates()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BTC", rates[0].Coin)
+	assert.Equal(t, 1.45e-06, rates[0].Estimate)
+}
+
+func TestSpotMarginService_GetLendingRates(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"BTC","estimate":1.45e-06,"previous":1.44e-06}]}`)
+	}
+
+	rates, err := c.SpotMargin.GetLendingRates()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.44e-06, rates[0].Previous)
+}
+
+func TestSpotMarginService_GetDailyBorrowedAmounts(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"BTC","size":120.1}]}`)
+	}
+
+	amounts, err := c.SpotMargin.GetDailyBorrowedAmounts()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 120.1, amounts[0].Size)
+}
+
+func TestSpotMarginService_GetMarketInfo(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.QueryArgs().Peek("market"))
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"BTC","borrowed":0,"free":3.87278021,"estimatedRate":1.45e-06,"previousRate":1.44e-06}]}`)
+	}
+
+	info, err := c.SpotMargin.GetMarketInfo("BTC/USD")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "BTC/USD", <-ch)
+	assert.Equal(t, 3.87278021, info[0].Free)
+}
+
+func TestSpotMarginService_GetBorrowHistory(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"BTC","cost":0.00047864470072,"rate":1.961096e-05,"size":24.407,"time":"2020-11-30T12:00:00+00:00"}]}`)
+	}
+
+	history, err := c.SpotMargin.GetBorrowHistory(nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 24.407, history[0].Size)
+	assert.Equal(t, int64(1606737600), history[0].Time.Unix())
+}
+
+func TestSpotMarginService_GetLendingHistory(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.QueryArgs().QueryString())
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"BTC","proceeds":0.00047864470072,"rate":1.961096e-05,"size":24.407,"time":"2020-11-30T12:00:00+00:00"}]}`)
+	}
+
+	history, err := c.SpotMargin.GetLendingHistory(&GetSpotMarginHistoryOptions{StartTime: 1606694400})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "start_time=1606694400", <-ch)
+	assert.Equal(t, 0.00047864470072, history[0].Proceeds)
+}
+
+func TestSpotMarginService_GetLendingOffers(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"BTC","rate":1e-06,"size":1.1}]}`)
+	}
+
+	offers, err := c.SpotMargin.GetLendingOffers()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1.1, offers[0].Size)
+}
+
+func TestSpotMarginService_GetLendingInfo(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"USD","lendable":10026.5,"locked":100.0,"minRate":1e-06,"offered":100.0}]}`)
+	}
+
+	info, err := c.SpotMargin.GetLendingInfo()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10026.5, info[0].Lendable)
+}
+
+func TestSpotMarginService_SubmitLendingOffer(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":null}`)
+		ch <- string(ctx.Request.Body())
+	}
+
+	err := c.SpotMargin.SubmitLendingOffer(&LendingOffer{Coin: "USD", Size: 10, Rate: 1e-06})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"coin":"USD","size":10,"rate":1e-06}`, <-ch)
+}
@@ -0,0 +1,114 @@
+package ftx
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type StakingService service
+
+const (
+	pathStakingStakes        = "%s/staking/stakes"
+	pathStakingUnstakes      = "%s/staking/unstake_requests"
+	pathStakingUnstake       = "%s/staking/unstake_requests/%d"
+	pathStakingBalances      = "%s/staking/balances"
+	pathStakingRewards       = "%s/staking/staking_rewards"
+	pathStakingStakeRequests = "%s/srm_stakes/stakes"
+)
+
+type Stake struct {
+	ID        int       `json:"id"`
+	Coin      string    `json:"coin"`
+	Size      float64   `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GetStakes FTX API docs: https://docs.ftx.com/#get-stakes
+func (s *StakingService) GetStakes() ([]Stake, error) {
+	u := fmt.Sprintf(pathStakingStakes, s.client.baseURL)
+
+	var out []Stake
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type UnstakeRequest struct {
+	ID        int       `json:"id"`
+	Coin      string    `json:"coin"`
+	Size      float64   `json:"size"`
+	Fee       float64   `json:"fee"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"createdAt"`
+	UnlockAt  time.Time `json:"unlockAt"`
+}
+
+// GetUnstakeRequests FTX API docs: https://docs.ftx.com/#unstake-request
+func (s *StakingService) GetUnstakeRequests() ([]UnstakeRequest, error) {
+	u := fmt.Sprintf(pathStakingUnstakes, s.client.baseURL)
+
+	var out []UnstakeRequest
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type StakePayload struct {
+	Coin string  `json:"coin"`
+	Size float64 `json:"size"`
+}
+
+// RequestUnstake FTX API docs: https://docs.ftx.com/#unstake-request-2
+func (s *StakingService) RequestUnstake(in *StakePayload) (*UnstakeRequest, error) {
+	u := fmt.Sprintf(pathStakingUnstakes, s.client.baseURL)
+
+	var out UnstakeRequest
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
+
+// CancelUnstakeRequest FTX API docs: https://docs.ftx.com/#cancel-unstake-request
+func (s *StakingService) CancelUnstakeRequest(id int) error {
+	u := fmt.Sprintf(pathStakingUnstake, s.client.baseURL, id)
+	return s.client.DoPrivate(u, http.MethodDelete, nil, nil)
+}
+
+type StakeBalance struct {
+	Coin               string  `json:"coin"`
+	LifetimeRewards    float64 `json:"lifetimeRewards"`
+	ScheduledToUnstake float64 `json:"scheduledToUnstake"`
+	Staked             float64 `json:"staked"`
+}
+
+// GetBalances FTX API docs: https://docs.ftx.com/#get-stake-balances
+func (s *StakingService) GetBalances() ([]StakeBalance, error) {
+	u := fmt.Sprintf(pathStakingBalances, s.client.baseURL)
+
+	var out []StakeBalance
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+type StakingReward struct {
+	ID        int       `json:"id"`
+	Coin      string    `json:"coin"`
+	Size      float64   `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// GetRewards FTX API docs: https://docs.ftx.com/#get-staking-rewards
+func (s *StakingService) GetRewards() ([]StakingReward, error) {
+	u := fmt.Sprintf(pathStakingRewards, s.client.baseURL)
+
+	var out []StakingReward
+	err := s.client.DoPrivate(u, http.MethodGet, nil, &out)
+	return out, err
+}
+
+// RequestStake FTX API docs: https://docs.ftx.com/#stake-request
+func (s *StakingService) RequestStake(in *StakePayload) (*Stake, error) {
+	u := fmt.Sprintf(pathStakingStakeRequests, s.client.baseURL)
+
+	var out Stake
+	err := s.client.DoPrivate(u, http.MethodPost, in, &out)
+	return &out, err
+}
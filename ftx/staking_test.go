@@ -0,0 +1,122 @@
+package ftx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestStakingService_GetStakes(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"SRM","createdAt":"2020-12-02T07:28:43.118185+00:00","id":1,"size":12.345}]}`)
+	}
+
+	stakes, err := c.Staking.GetStakes()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "SRM", stakes[0].Coin)
+	assert.Equal(t, int64(1606894123), stakes[0].CreatedAt.Unix())
+}
+
+func TestStakingService_GetUnstakeRequests(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"SRM","createdAt":"2020-12-02T07:28:43.118185+00:00","fee":3.0,"id":1,"size":12.345,"status":"pending","unlockAt":"2020-12-09T07:28:43.118185+00:00"}]}`)
+	}
+
+	requests, err := c.Staking.GetUnstakeRequests()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", requests[0].Status)
+	assert.Equal(t, 3.0, requests[0].Fee)
+	assert.Equal(t, int64(1607498923), requests[0].UnlockAt.Unix())
+}
+
+func TestStakingService_RequestUnstake(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"coin":"SRM","createdAt":"2020-12-02T07:28:43.118185+00:00","id":1,"size":0.1,"status":"pending","unlockAt":"2020-12-09T07:28:43.118185+00:00"}}`)
+	}
+
+	request, err := c.Staking.RequestUnstake(&StakePayload{Coin: "SRM", Size: 0.1})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"coin":"SRM","size":0.1}`, <-ch)
+	assert.Equal(t, 1, request.ID)
+}
+
+func TestStakingService_CancelUnstakeRequest(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 1)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.Header.Method()) + " " + string(ctx.Request.URI().Path())
+		ctx.SetBodyString(`{"success":true,"result":"Cancelled"}`)
+	}
+
+	err := c.Staking.CancelUnstakeRequest(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE /staking/unstake_requests/1", <-ch)
+}
+
+func TestStakingService_GetBalances(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"SRM","lifetimeRewards":0.1,"scheduledToUnstake":0.2,"staked":12.3}]}`)
+	}
+
+	balances, err := c.Staking.GetBalances()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 12.3, balances[0].Staked)
+}
+
+func TestStakingService_GetRewards(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ctx.SetBodyString(`{"success":true,"result":[{"coin":"SRM","createdAt":"2020-12-02T07:28:43.118185+00:00","id":1,"size":0.01}]}`)
+	}
+
+	rewards, err := c.Staking.GetRewards()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.01, rewards[0].Size)
+}
+
+func TestStakingService_RequestStake(t *testing.T) {
+	c, srv, teardown := setup()
+	defer teardown()
+
+	ch := make(chan string, 2)
+
+	srv.Handler = func(ctx *fasthttp.RequestCtx) {
+		ch <- string(ctx.Request.URI().Path())
+		ch <- string(ctx.Request.Body())
+		ctx.SetBodyString(`{"success":true,"result":{"coin":"SRM","createdAt":"2020-12-02T07:28:43.118185+00:00","id":1,"size":0.1}}`)
+	}
+
+	stake, err := c.Staking.RequestStake(&StakePayload{Coin: "SRM", Size: 0.1})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/srm_stakes/stakes", <-ch)
+	assert.JSONEq(t, `{"coin":"SRM","size":0.1}`, <-ch)
+	assert.Equal(t, 0.1, stake.Size)
+}
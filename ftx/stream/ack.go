@@ -0,0 +1,288 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudingcity/go-ftx/ftx/internal/jsonscan"
+)
+
+// AckError is returned by LoginWait and SubscribeWait when the exchange
+// rejects the request.
+type AckError struct {
+	Op      string // "login" or "subscribe"
+	Channel string
+	Market  string
+	Code    int
+	Msg     string
+}
+
+func (e *AckError) Error() string {
+	if e.Channel == "" {
+		return fmt.Sprintf("%s: %s (%d)", e.Op, e.Msg, e.Code)
+	}
+	return fmt.Sprintf("%s %s %s: %s (%d)", e.Op, e.Channel, e.Market, e.Msg, e.Code)
+}
+
+// ackWaiter is a request that may be answered by an error, waited for by a
+// blocking call or not.
+type ackWaiter struct {
+	op      string
+	channel string
+	market  string
+	ping    int           // number of the ping sent after a waited login
+	done    chan struct{} // nil if nobody waits for the request
+	err     error
+}
+
+// LoginWait logs in like Login and waits until the login is accepted.
+//
+// FTX doesn't acknowledge a successful login, so a ping is sent after it: the
+// pong of that ping arriving without an error before it means the login was
+// accepted. The pong is not returned by Recv.
+func (c *Conn) LoginWait(ctx context.Context) error {
+	return c.LoginSubaccountWait(ctx, c.subaccount)
+}
+
+// LoginSubaccountWait logs in like LoginSubaccount and waits until the login is accepted.
+func (c *Conn) LoginSubaccountWait(ctx context.Context, subaccount string) error {
+	req := connRequest{OP: "login"}
+	if err := c.auth(&req, subaccount); err != nil {
+		return err
+	}
+
+	w := newWaiter()
+	if err := c.writeAck(&req, w); err != nil {
+		c.removeWaiter(w)
+		return err
+	}
+	if err := c.writeAck(&connRequest{OP: "ping"}, w); err != nil {
+		c.removeWaiter(w)
+		return err
+	}
+	return c.await(ctx, w)
+}
+
+// SubscribeWait subscribes like Subscribe and waits for the subscribed
+// response. The response, or the error rejecting the subscription, is not
+// returned by Recv. Other messages received meanwhile are kept for Recv.
+//
+// FTX errors don't name the request they answer, so an error is attributed to
+// the oldest request without an answer. If that one isn't waited for, e.g. a
+// Subscribe, the error is returned by Recv.
+func (c *Conn) SubscribeWait(ctx context.Context, channel string, market ...string) error {
+	req := connRequest{OP: "subscribe", Channel: channel}
+	if len(market) >= 1 {
+		req.Market = market[0]
+	}
+
+	w := newWaiter()
+	if err := c.writeAck(&req, w); err != nil {
+		c.removeWaiter(w)
+		return err
+	}
+	return c.await(ctx, w)
+}
+
+func newWaiter() *ackWaiter {
+	return &ackWaiter{done: make(chan struct{})}
+}
+
+// track records req, sent for w if it isn't nil, in the order of writing.
+// Waited logins and all (un)subscriptions are kept until they are answered,
+// pings are numbered to match their pongs. Other logins are not tracked: FTX
+// doesn't answer successful ones, so they would take the errors of later
+// requests.
+func (c *Conn) track(req *connRequest, w *ackWaiter) {
+	c.ackMu.Lock()
+	defer c.ackMu.Unlock()
+
+	switch req.OP {
+	case "ping":
+		c.pings++
+		if w != nil {
+			w.ping = c.pings
+			if w.done != nil && !c.waiting(w) {
+				c.hidePong(w) // the login failed before its ping was sent
+			}
+		}
+	case "login", "subscribe", "unsubscribe":
+		if w == nil {
+			if req.OP == "login" {
+				return
+			}
+			w = &ackWaiter{}
+		}
+		w.op, w.channel, w.market = req.OP, req.Channel, req.Market
+		c.waiters = append(c.waiters, w)
+	}
+}
+
+func (c *Conn) waiting(w *ackWaiter) bool {
+	for _, v := range c.waiters {
+		if v == w {
+			return true
+		}
+	}
+	return false
+}
+
+// hidePong keeps the pong of the login ping of w from Recv. ackMu must be held.
+func (c *Conn) hidePong(w *ackWaiter) {
+	if w.op != "login" || w.ping == 0 {
+		return
+	}
+	if c.hiddenPongs == nil {
+		c.hiddenPongs = make(map[int]bool)
+	}
+	c.hiddenPongs[w.ping] = true
+}
+
+// removeWaiter removes w and reports whether it was still pending.
+func (c *Conn) removeWaiter(w *ackWaiter) bool {
+	c.ackMu.Lock()
+	defer c.ackMu.Unlock()
+
+	for i, v := range c.waiters {
+		if v == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// await waits for w to be resolved. If nobody else is receiving, it reads
+// frames itself and keeps those that don't resolve a waiter for RecvRaw.
+func (c *Conn) await(ctx context.Context, w *ackWaiter) error {
+	for {
+		select {
+		case <-w.done:
+			return w.err
+		case <-ctx.Done():
+			if c.removeWaiter(w) {
+				c.ackMu.Lock()
+				c.hidePong(w)
+				c.ackMu.Unlock()
+			}
+			return ctx.Err()
+		case c.readSem <- struct{}{}:
+			go func() {
+				defer func() { <-c.readSem }()
+
+				msg, err := c.readFrame(nil)
+				if err != nil {
+					c.failWaiters(err)
+					return
+				}
+				if !c.resolve(msg) {
+					c.pending = append(c.pending, msg)
+				}
+			}()
+		}
+	}
+}
+
+// resolve resolves the request answered by msg and reports whether msg
+// answered a blocking call, so that it isn't returned by Recv.
+func (c *Conn) resolve(msg []byte) bool {
+	c.ackMu.Lock()
+	defer c.ackMu.Unlock()
+
+	// Pongs are counted while a ping is unanswered, even if nobody waits.
+	if len(c.waiters) == 0 && c.pongs == c.pings {
+		return false
+	}
+
+	h, err := scanHeader(msg)
+	if err != nil {
+		return false
+	}
+
+	switch string(h.typ) {
+	case "pong":
+		c.pongs++
+		hidden := c.hiddenPongs[c.pongs]
+		delete(c.hiddenPongs, c.pongs)
+		// A login is resolved by the pong of its own ping only.
+		for i, w := range c.waiters {
+			if w.op == "login" && w.ping == c.pongs {
+				c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+				close(w.done)
+				return true
+			}
+		}
+		return hidden
+	case "subscribed", "unsubscribed":
+		op := strings.TrimSuffix(string(h.typ), "d")
+		for i, w := range c.waiters {
+			if w.op == op && w.channel == string(h.channel) && w.market == string(h.market) {
+				c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+				if w.done == nil {
+					return false
+				}
+				close(w.done)
+				return true
+			}
+		}
+	case "error":
+		if len(c.waiters) == 0 {
+			return false
+		}
+		w := c.waiters[0]
+		c.waiters = c.waiters[1:]
+		if w.done == nil {
+			return false
+		}
+		w.err = &AckError{Op: w.op, Channel: w.channel, Market: w.market, Code: h.code, Msg: string(h.msg)}
+		c.hidePong(w) // the pong of the login ping is still to come
+		close(w.done)
+		return true
+	}
+	return false
+}
+
+func (c *Conn) failWaiters(err error) {
+	c.ackMu.Lock()
+	defer c.ackMu.Unlock()
+
+	for _, w := range c.waiters {
+		if w.done != nil {
+			w.err = err
+			close(w.done)
+		}
+	}
+	c.waiters = nil
+}
+
+// header is the part of a message before its data.
+type header struct {
+	typ, channel, market, msg []byte
+	code                      int
+}
+
+// scanHeader scans the header of msg, skipping its data.
+func scanHeader(msg []byte) (header, error) {
+	var h header
+	s := jsonscan.New(msg)
+	err := s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "type":
+			h.typ, err = s.Str()
+		case "channel":
+			h.channel, err = s.Str()
+		case "market":
+			h.market, err = s.Str()
+		case "code":
+			h.code, err = s.Int()
+		case "msg":
+			h.msg, err = s.Str()
+		default:
+			err = s.Skip()
+		}
+		return err
+	})
+	return h, err
+}
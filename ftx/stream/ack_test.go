@@ -0,0 +1,188 @@
+package stream
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/auth"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupExchange serves a websocket answering requests like FTX. Every
+// subscription is preceded by an unrelated ticker update, markets named "BAD"
+// and logins with key "bad" are rejected and requests for market "SLOW" are
+// never answered.
+func setupExchange() (conn *Conn, teardown func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for {
+			var req connRequest
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+			var resp []connResponse
+			switch {
+			case req.OP == "ping":
+				resp = append(resp, connResponse{Type: "pong"})
+			case req.OP == "login" && req.Args.Key == "bad":
+				resp = append(resp, connResponse{Type: "error", Code: 400, Msg: "Invalid login credentials"})
+			case req.OP == "subscribe" && req.Market == "SLOW":
+			case req.OP == "subscribe" && req.Market == "BAD":
+				resp = append(resp, connResponse{Type: "error", Code: 404, Msg: "No such market: BAD"})
+			case req.OP == "subscribe":
+				resp = append(resp,
+					connResponse{Type: "update", Channel: ChannelTicker, Market: "ETH-PERP", Data: []byte(`{"bid":1}`)},
+					connResponse{Type: "subscribed", Channel: req.Channel, Market: req.Market},
+				)
+			}
+			for _, v := range resp {
+				if err := ws.WriteJSON(v); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	u, _ := url.Parse(srv.URL)
+	u.Scheme = "ws"
+
+	ws, _, _ := websocket.DefaultDialer.Dial(u.String(), nil)
+	conn = New(ws, "api-key", []byte("api-secret"), "")
+
+	return conn, func() {
+		srv.Close()
+		_ = ws.Close()
+	}
+}
+
+func TestConn_SubscribeWait(t *testing.T) {
+	conn, teardown := setupExchange()
+	defer teardown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	t.Run("success", func(t *testing.T) {
+		err := conn.SubscribeWait(ctx, ChannelTrades, "BTC-PERP")
+		assert.NoError(t, err)
+
+		// The unrelated update is kept for Recv, the subscribed response is not.
+		msg, err := conn.Recv()
+		assert.NoError(t, err)
+		assert.IsType(t, Ticker{}, msg)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		err := conn.SubscribeWait(ctx, ChannelTrades, "BAD")
+
+		var ackErr *AckError
+		assert.ErrorAs(t, err, &ackErr)
+		assert.Equal(t, &AckError{Op: "subscribe", Channel: ChannelTrades, Market: "BAD", Code: 404, Msg: "No such market: BAD"}, ackErr)
+	})
+
+	t.Run("while receiving", func(t *testing.T) {
+		recv := make(chan interface{}, 1)
+		go func() {
+			msg, _ := conn.Recv()
+			recv <- msg
+		}()
+
+		err := conn.SubscribeWait(ctx, ChannelOrderBook, "BTC-PERP")
+		assert.NoError(t, err)
+		assert.IsType(t, Ticker{}, <-recv)
+	})
+
+	t.Run("after plain subscribe", func(t *testing.T) {
+		assert.NoError(t, conn.Subscribe(ChannelTrades, "BAD"))
+
+		err := conn.SubscribeWait(ctx, ChannelTrades, "ETH-PERP")
+		assert.NoError(t, err, "the error answers the plain subscribe")
+
+		msg, err := conn.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, Error{Type: "error", Code: 404, Msg: "No such market: BAD"}, msg)
+		// The update preceding the subscribed response of ETH-PERP.
+		msg, err = conn.Recv()
+		assert.NoError(t, err)
+		assert.IsType(t, Ticker{}, msg)
+	})
+
+	t.Run("after plain login", func(t *testing.T) {
+		assert.NoError(t, conn.Login())
+
+		err := conn.SubscribeWait(ctx, ChannelTrades, "BAD")
+
+		var ackErr *AckError
+		assert.ErrorAs(t, err, &ackErr)
+		assert.Equal(t, "BAD", ackErr.Market)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		err := conn.SubscribeWait(ctx, ChannelTrades, "SLOW")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Empty(t, conn.waiters)
+	})
+}
+
+func TestConn_LoginWait(t *testing.T) {
+	conn, teardown := setupExchange()
+	defer teardown()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	t.Run("success", func(t *testing.T) {
+		err := conn.LoginWait(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		conn.key = "bad"
+		conn.signer = auth.NewHMAC([]byte("api-secret"))
+
+		err := conn.LoginWait(ctx)
+
+		var ackErr *AckError
+		assert.ErrorAs(t, err, &ackErr)
+		assert.Equal(t, "login: Invalid login credentials (400)", err.Error())
+
+		// The pong answering the login ping is not attributed to a later call.
+		conn.key = "api-key"
+		assert.NoError(t, conn.LoginWait(ctx))
+	})
+
+	t.Run("after pings", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, conn.Ping())
+			msg, err := conn.Recv()
+			assert.NoError(t, err)
+			assert.Equal(t, Pong{Type: "pong"}, msg)
+		}
+		assert.NoError(t, conn.LoginWait(ctx))
+	})
+
+	t.Run("keepalive pong", func(t *testing.T) {
+		assert.NoError(t, conn.Ping())
+		assert.NoError(t, conn.LoginWait(ctx))
+
+		// The pong of the keepalive ping is returned by Recv, the pong of the
+		// login ping is not.
+		msg, err := conn.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, Pong{Type: "pong"}, msg)
+		assert.Empty(t, conn.waiters)
+		assert.Empty(t, conn.hiddenPongs)
+		assert.Equal(t, conn.pings, conn.pongs)
+	})
+}
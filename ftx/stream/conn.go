@@ -2,15 +2,15 @@ package stream
 
 import (
 	"context"
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/cloudingcity/go-ftx/ftx/auth"
 	"github.com/gorilla/websocket"
 )
 
@@ -48,17 +48,101 @@ type connResponse struct {
 type Conn struct {
 	conn       *websocket.Conn
 	key        string
-	secret     []byte
+	signer     auth.Signer
 	subaccount string
+	offset     func() time.Duration
+	hook       func(Event)
+	watch      *watchdog
+
+	writeMu sync.Mutex
+
+	readSem chan struct{} // held while reading a frame
+	pending [][]byte      // frames read by blocking calls, guarded by readSem
+	buf     []byte        // read buffer of RecvFrame, guarded by readSem
+
+	ackMu       sync.Mutex
+	waiters     []*ackWaiter // unanswered waited logins and (un)subscriptions, oldest first
+	pings       int          // pings sent
+	pongs       int          // pongs received
+	hiddenPongs map[int]bool // pongs of failed logins, not returned by Recv
 }
 
-func New(conn *websocket.Conn, key string, secret []byte, subaccount string) *Conn {
-	return &Conn{conn: conn, key: key, secret: secret, subaccount: subaccount}
+const (
+	OpSend = "send"
+	OpRecv = "recv"
+)
+
+// Event describes a frame sent or received by Conn.
+type Event struct {
+	Op      string // OpSend or OpRecv
+	Request string // op of a sent request, e.g. "subscribe"
+	Channel string // channel of a sent request
+	Market  string // market of a sent request
+	Bytes   int
+	Latency time.Duration // time spent writing the frame, or waiting for it to be received
+	Err     error
+}
+
+type Option func(*Conn)
+
+// WithSigner signs the login request with signer instead of secret.
+func WithSigner(signer auth.Signer) Option {
+	return func(c *Conn) {
+		c.signer = signer
+	}
+}
+
+// WithClockOffset adds the offset returned by f to the login timestamp.
+func WithClockOffset(f func() time.Duration) Option {
+	return func(c *Conn) {
+		c.offset = f
+	}
+}
+
+// WithHook calls f for every frame sent or received, e.g. for logging or metrics.
+func WithHook(f func(Event)) Option {
+	return func(c *Conn) {
+		c.hook = f
+	}
+}
+
+// WithWriteCompression compresses the requests sent with the given
+// compress/flate level if the connection negotiated permessage-deflate. An
+// invalid level keeps the default one.
+// Requests are small, so ftx.Client.Connect compresses only received messages
+// by default.
+func WithWriteCompression(level int) Option {
+	return func(c *Conn) {
+		c.conn.EnableWriteCompression(true)
+		_ = c.conn.SetCompressionLevel(level)
+	}
+}
+
+// New wraps conn. Messages received compressed with permessage-deflate, see
+// websocket.Dialer.EnableCompression, are decompressed transparently. The
+// write compression of conn is kept unless WithWriteCompression is passed.
+func New(conn *websocket.Conn, key string, secret []byte, subaccount string, opts ...Option) *Conn {
+	c := &Conn{conn: conn, key: key, subaccount: subaccount, readSem: make(chan struct{}, 1)}
+	if len(secret) > 0 {
+		c.signer = auth.NewHMAC(secret)
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *Conn) Recv() (interface{}, error) {
+	msg, err := c.RecvRaw()
+	if err != nil {
+		return nil, err
+	}
+	return decode(msg)
+}
+
+func decode(msg []byte) (interface{}, error) {
 	var resp connResponse
-	if err := c.conn.ReadJSON(&resp); err != nil {
+	if err := json.Unmarshal(msg, &resp); err != nil {
 		return nil, err
 	}
 
@@ -101,12 +185,106 @@ func (c *Conn) Recv() (interface{}, error) {
 }
 
 func (c *Conn) RecvRaw() ([]byte, error) {
-	_, msg, err := c.conn.ReadMessage()
+	c.readSem <- struct{}{}
+	defer func() { <-c.readSem }()
+
+	return c.next(nil)
+}
+
+// next returns the next frame not answering a blocking call, read into buf
+// if it isn't nil. readSem must be held.
+func (c *Conn) next(buf []byte) ([]byte, error) {
+	for {
+		if len(c.pending) > 0 {
+			msg := c.pending[0]
+			c.pending = c.pending[1:]
+			return msg, nil
+		}
+
+		msg, err := c.readFrame(buf)
+		if err != nil {
+			c.failWaiters(err)
+			return msg, err
+		}
+		if !c.resolve(msg) {
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads a frame into buf, or a new slice if buf is nil.
+func (c *Conn) readFrame(buf []byte) ([]byte, error) {
+	start := time.Now()
+	if c.watch != nil && c.watch.cfg.ReadTimeout > 0 {
+		if err := c.conn.SetReadDeadline(start.Add(c.watch.cfg.ReadTimeout)); err != nil {
+			return nil, err
+		}
+	}
+	var msg []byte
+	var err error
+	if buf == nil {
+		_, msg, err = c.conn.ReadMessage()
+	} else {
+		msg, err = c.readInto(buf)
+	}
+	if c.hook != nil {
+		c.hook(Event{Op: OpRecv, Bytes: len(msg), Latency: time.Since(start), Err: err})
+	}
+	if err == nil && c.watch != nil {
+		c.watch.received(msg, time.Now())
+	}
 	return msg, err
 }
 
+func (c *Conn) readInto(buf []byte) ([]byte, error) {
+	_, r, err := c.conn.NextReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Conn) write(req *connRequest) error {
+	return c.writeAck(req, nil)
+}
+
+// writeAck writes req sent for w, or for nobody if w is nil, see track.
+func (c *Conn) writeAck(req *connRequest, w *ackWaiter) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	start := time.Now()
+	c.track(req, w)
+	if c.watch != nil {
+		c.watch.sent(req, start)
+	}
+	err = c.conn.WriteMessage(websocket.TextMessage, b)
+	latency := time.Since(start)
+	c.writeMu.Unlock()
+
+	if c.hook != nil {
+		c.hook(Event{Op: OpSend, Request: req.OP, Channel: req.Channel, Market: req.Market, Bytes: len(b), Latency: latency, Err: err})
+	}
+	return err
+}
+
 func (c *Conn) Ping() error {
-	return c.conn.WriteJSON(&connRequest{OP: "ping"})
+	return c.write(&connRequest{OP: "ping"})
 }
 
 func (c *Conn) PingRegular(ctx context.Context, duration time.Duration) {
@@ -126,56 +304,57 @@ func (c *Conn) PingRegular(ctx context.Context, duration time.Duration) {
 }
 
 func (c *Conn) Login() error {
+	return c.LoginSubaccount(c.subaccount)
+}
+
+// LoginSubaccount logs in on behalf of subaccount, or the main account if subaccount is empty.
+func (c *Conn) LoginSubaccount(subaccount string) error {
 	req := connRequest{OP: "login"}
-	if err := c.auth(&req); err != nil {
+	if err := c.auth(&req, subaccount); err != nil {
 		return err
 	}
-	return c.conn.WriteJSON(&req)
+	return c.write(&req)
 }
 
-func (c *Conn) auth(req *connRequest) error {
-	if c.key == "" || len(c.secret) == 0 {
+func (c *Conn) auth(req *connRequest, subaccount string) error {
+	if c.key == "" || c.signer == nil {
 		return errors.New("API key and secret not configured")
 	}
 
 	t := unixTime()
+	if c.offset != nil {
+		t += c.offset().Milliseconds()
+	}
 	ts := strconv.FormatInt(t, 10)
 
-	sign := []byte(ts + "websocket_login")
-	hash := hmac.New(sha256.New, c.secret)
-	hash.Write(sign)
+	sign, err := c.signer.Sign([]byte(ts + "websocket_login"))
+	if err != nil {
+		return err
+	}
 
 	req.Args = &args{
 		Key:  c.key,
-		Sign: hex.EncodeToString(hash.Sum(nil)),
+		Sign: sign,
 		Time: t,
 	}
-	if c.subaccount != "" {
-		req.Args.SubAccount = c.subaccount
+	if subaccount != "" {
+		req.Args.SubAccount = subaccount
 	}
 	return nil
 }
 
 func (c *Conn) Subscribe(channel string, market ...string) error {
 	if len(market) >= 1 {
-		return c.conn.WriteJSON(
-			&connRequest{OP: "subscribe", Channel: channel, Market: market[0]},
-		)
+		return c.write(&connRequest{OP: "subscribe", Channel: channel, Market: market[0]})
 	}
-	return c.conn.WriteJSON(
-		&connRequest{OP: "subscribe", Channel: channel},
-	)
+	return c.write(&connRequest{OP: "subscribe", Channel: channel})
 }
 
 func (c *Conn) Unsubscribe(channel string, market ...string) error {
 	if len(market) >= 1 {
-		return c.conn.WriteJSON(
-			&connRequest{OP: "unsubscribe", Channel: channel, Market: market[0]},
-		)
+		return c.write(&connRequest{OP: "unsubscribe", Channel: channel, Market: market[0]})
 	}
-	return c.conn.WriteJSON(
-		&connRequest{OP: "unsubscribe", Channel: channel},
-	)
+	return c.write(&connRequest{OP: "unsubscribe", Channel: channel})
 }
 
 func (c *Conn) Close() error {
@@ -1,10 +1,20 @@
 package stream
 
 import (
+	"compress/flate"
 	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/cloudingcity/go-ftx/ftx/auth"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,7 +55,7 @@ func TestConn_Login(t *testing.T) {
 	)
 	unixTime = func() int64 { return 1557246346499 }
 	conn.key = key
-	conn.secret = []byte(secret)
+	conn.signer = auth.NewHMAC([]byte(secret))
 
 	err := conn.Login()
 	assert.NoError(t, err)
@@ -56,6 +66,24 @@ func TestConn_Login(t *testing.T) {
 	assert.JSONEq(t, `{"op":"login", "args":{"key":"api-key","sign":"d10b5a67a1a941ae9463a60b285ae845cdeac1b11edc7da9977bef0228b96de9","time":1557246346499}}`, string(resp))
 }
 
+func TestConn_LoginSubaccount(t *testing.T) {
+	conn, _, teardown := setup()
+	defer teardown()
+
+	unixTime = func() int64 { return 1557246346499 }
+	conn.key = "api-key"
+	conn.signer = auth.NewHMAC([]byte("Y2QTHI23f23f23jfjas23f23To0RfUwX3H42fvN-"))
+	conn.subaccount = "main"
+
+	err := conn.LoginSubaccount("my-account")
+	assert.NoError(t, err)
+
+	resp, err := conn.RecvRaw()
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"op":"login", "args":{"key":"api-key","sign":"d10b5a67a1a941ae9463a60b285ae845cdeac1b11edc7da9977bef0228b96de9","time":1557246346499,"subaccount":"my-account"}}`, string(resp))
+}
+
 func TestConn_Subscribe(t *testing.T) {
 	conn, _, teardown := setup()
 	defer teardown()
@@ -227,3 +255,210 @@ func TestConn_Recv(t *testing.T) {
 		assert.Equal(t, 123, got.Data.ID)
 	})
 }
+
+func TestWithHook(t *testing.T) {
+	conn, _, teardown := setup()
+	defer teardown()
+
+	var events []Event
+	WithHook(func(e Event) { events = append(events, e) })(conn)
+
+	assert.NoError(t, conn.Subscribe(ChannelTrades, "BTC/USD"))
+	_, err := conn.RecvRaw()
+	assert.NoError(t, err)
+
+	assert.Len(t, events, 2)
+	assert.Equal(t, OpSend, events[0].Op)
+	assert.Equal(t, "subscribe", events[0].Request)
+	assert.Equal(t, ChannelTrades, events[0].Channel)
+	assert.Equal(t, "BTC/USD", events[0].Market)
+	assert.Equal(t, OpRecv, events[1].Op)
+	assert.Equal(t, events[0].Bytes, events[1].Bytes)
+}
+
+// countingConn counts the bytes read and written on the wire.
+type countingConn struct {
+	net.Conn
+	read, written int64
+}
+
+func (c *countingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	atomic.AddInt64(&c.read, int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	atomic.AddInt64(&c.written, int64(n))
+	return n, err
+}
+
+func TestConn_Compression(t *testing.T) {
+	var bids []string
+	for i := 0; i < 500; i++ {
+		bids = append(bids, fmt.Sprintf("[%d.5, 1.25]", 40000+i))
+	}
+	frame := `{"channel": "orderbook", "market": "BTC-PERP", "type": "partial", "data": {"action": "partial", "bids": [` + strings.Join(bids, ", ") + `], "asks": []}}`
+
+	var extensions atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		extensions.Store(r.Header.Get("Sec-Websocket-Extensions"))
+		ws, err := (&websocket.Upgrader{EnableCompression: true}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		if err := ws.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+			return
+		}
+		for {
+			mt, msg, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := ws.WriteMessage(mt, msg); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	u.Scheme = "ws"
+
+	dial := func(writeCompression bool, opts ...Option) (*Conn, *countingConn) {
+		cc := &countingConn{}
+		dialer := websocket.Dialer{
+			EnableCompression: true,
+			NetDial: func(network, addr string) (net.Conn, error) {
+				conn, err := net.Dial(network, addr)
+				cc.Conn = conn
+				return cc, err
+			},
+		}
+		ws, _, err := dialer.Dial(u.String(), nil)
+		assert.NoError(t, err)
+		ws.EnableWriteCompression(writeCompression)
+		return New(ws, "", nil, "", opts...), cc
+	}
+	market := strings.Repeat("BTC-PERP", 100)
+
+	t.Run("receive", func(t *testing.T) {
+		conn, cc := dial(false)
+		defer conn.Close()
+		assert.Contains(t, extensions.Load(), "permessage-deflate")
+
+		var f Frame
+		assert.NoError(t, conn.RecvFrame(&f))
+		assert.Len(t, f.OrderBook.Data.Bids, 500)
+		assert.Less(t, atomic.LoadInt64(&cc.read), int64(len(frame))/2)
+
+		// Requests are not compressed if the connection doesn't.
+		written := atomic.LoadInt64(&cc.written)
+		assert.NoError(t, conn.Subscribe(ChannelOrderBook, market))
+		assert.Greater(t, atomic.LoadInt64(&cc.written)-written, int64(len(market)))
+
+		msg, err := conn.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, General{Channel: ChannelOrderBook, Market: market}, msg)
+	})
+
+	t.Run("write compression", func(t *testing.T) {
+		conn, cc := dial(false, WithWriteCompression(flate.BestSpeed))
+		defer conn.Close()
+
+		_, err := conn.RecvRaw()
+		assert.NoError(t, err)
+		written := atomic.LoadInt64(&cc.written)
+		assert.NoError(t, conn.Subscribe(ChannelOrderBook, market))
+		assert.Less(t, atomic.LoadInt64(&cc.written)-written, int64(len(market))/2)
+	})
+
+	t.Run("connection setting", func(t *testing.T) {
+		conn, cc := dial(true)
+		defer conn.Close()
+
+		_, err := conn.RecvRaw()
+		assert.NoError(t, err)
+		written := atomic.LoadInt64(&cc.written)
+		assert.NoError(t, conn.Subscribe(ChannelOrderBook, market))
+		assert.Less(t, atomic.LoadInt64(&cc.written)-written, int64(len(market))/2)
+	})
+}
+
+func BenchmarkDecode(b *testing.B) {
+	for name, frame := range map[string]string{"orderbook": orderBookFrame, "trades": tradesFrame, "ticker": tickerFrame} {
+		msg := []byte(frame)
+
+		b.Run(name+"/decode", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := decode(msg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(name+"/DecodeFrame", func(b *testing.B) {
+			b.ReportAllocs()
+			var f Frame
+			for i := 0; i < b.N; i++ {
+				if err := DecodeFrame(msg, &f); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// setupFlood serves a websocket sending frame n times.
+func setupFlood(frame []byte, n int) (conn *Conn, teardown func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		for i := 0; i < n; i++ {
+			if err := ws.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		}
+		_, _, _ = ws.ReadMessage()
+	}))
+	u, _ := url.Parse(srv.URL)
+	u.Scheme = "ws"
+
+	ws, _, _ := websocket.DefaultDialer.Dial(u.String(), nil)
+	return New(ws, "", nil, ""), func() {
+		_ = ws.Close()
+		srv.Close()
+	}
+}
+
+func BenchmarkConn_Recv(b *testing.B) {
+	conn, teardown := setupFlood([]byte(orderBookFrame), b.N)
+	defer teardown()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := conn.Recv(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkConn_RecvFrame(b *testing.B) {
+	conn, teardown := setupFlood([]byte(orderBookFrame), b.N)
+	defer teardown()
+
+	var f Frame
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := conn.RecvFrame(&f); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package stream
+
+import (
+	"math"
+	"time"
+
+	"github.com/cloudingcity/go-ftx/ftx/internal/jsonscan"
+)
+
+// Frame is a reusable destination for RecvFrame and DecodeFrame. Only the
+// message matching Channel is set. Its slices and pointers are reused, so a
+// Frame is only valid until the next call.
+type Frame struct {
+	Type    string
+	Channel string
+	Market  string
+	Code    int
+	Msg     string
+
+	OrderBook OrderBook
+	Trade     Trade
+	Ticker    Ticker
+	// Other is the message decoded like Recv for channels without a fast path, e.g. fills and orders.
+	Other interface{}
+}
+
+// tradeData is the element type of Trade.Data.
+type tradeData = struct {
+	ID          int       `json:"id"`
+	Liquidation bool      `json:"liquidation"`
+	Price       float64   `json:"price"`
+	Side        string    `json:"side"`
+	Size        float64   `json:"size"`
+	Time        time.Time `json:"time"`
+}
+
+// RecvFrame receives the next message into f. Unlike Recv it reuses a read
+// buffer and f, and parses orderbook, trades and ticker messages in one pass,
+// so steady-state receiving only allocates for websocket bookkeeping.
+func (c *Conn) RecvFrame(f *Frame) error {
+	c.readSem <- struct{}{}
+	defer func() { <-c.readSem }()
+
+	msg, err := c.next(c.buf[:0])
+	if err != nil {
+		return err
+	}
+	if cap(msg) > cap(c.buf) {
+		c.buf = msg[:0]
+	}
+	return DecodeFrame(msg, f)
+}
+
+// DecodeFrame decodes msg into f, see Frame. Times of trades are in UTC.
+func DecodeFrame(msg []byte, f *Frame) error {
+	s := jsonscan.New(msg)
+
+	var typ, channel, market, text, data []byte
+	var code int
+	var parsed bool
+	err := s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "type":
+			typ, err = s.Str()
+		case "channel":
+			channel, err = s.Str()
+		case "market":
+			market, err = s.Str()
+		case "code":
+			code, err = s.Int()
+		case "msg":
+			text, err = s.Str()
+		case "data":
+			if fastChannel(channel) {
+				parsed = true
+				return f.decodeData(s, channel)
+			}
+			s.WS()
+			start := s.Pos()
+			err = s.Skip()
+			data = msg[start:s.Pos()]
+		default:
+			err = s.Skip()
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	f.Type = intern(typ, f.Type)
+	f.Channel = intern(channel, f.Channel)
+	f.Market = intern(market, f.Market)
+	f.Msg = intern(text, f.Msg)
+	f.Code = code
+	f.Other = nil
+
+	if data != nil && !parsed {
+		if fastChannel(channel) {
+			err = f.decodeData(jsonscan.New(data), channel)
+		} else {
+			f.Other, err = decode(msg)
+		}
+	}
+
+	g := General{Type: f.Type, Channel: f.Channel, Market: f.Market}
+	switch f.Channel {
+	case ChannelOrderBook:
+		f.OrderBook.General = g
+	case ChannelTrades:
+		f.Trade.General = g
+	case ChannelTicker:
+		f.Ticker.General = g
+	}
+	return err
+}
+
+func fastChannel(channel []byte) bool {
+	switch string(channel) {
+	case ChannelOrderBook, ChannelTrades, ChannelTicker:
+		return true
+	}
+	return false
+}
+
+func (f *Frame) decodeData(s *jsonscan.Scanner, channel []byte) error {
+	switch string(channel) {
+	case ChannelOrderBook:
+		return decodeOrderBook(s, &f.OrderBook)
+	case ChannelTrades:
+		return decodeTrades(s, &f.Trade)
+	default:
+		return decodeTicker(s, &f.Ticker)
+	}
+}
+
+func decodeOrderBook(s *jsonscan.Scanner, ob *OrderBook) error {
+	d := &ob.Data
+	d.Checksum = 0
+	// Levels missing from the message must not be left from the previous one.
+	d.Bids, d.Asks = d.Bids[:0], d.Asks[:0]
+
+	var action []byte
+	var hasTime bool
+	err := s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "action":
+			action, err = s.Str()
+		case "bids":
+			d.Bids, err = decodeLevels(s, d.Bids)
+		case "asks":
+			d.Asks, err = decodeLevels(s, d.Asks)
+		case "checksum":
+			d.Checksum, err = s.Int()
+		case "time":
+			if d.Time == nil {
+				d.Time = new(Time)
+			}
+			hasTime = true
+			err = decodeUnixTime(s, d.Time)
+		default:
+			err = s.Skip()
+		}
+		return err
+	})
+	d.Action = intern(action, d.Action)
+	if !hasTime {
+		d.Time = nil
+	}
+	return err
+}
+
+func decodeTrades(s *jsonscan.Scanner, tr *Trade) error {
+	tr.Data = tr.Data[:0]
+	return s.Array(func() error {
+		n := len(tr.Data)
+		if n < cap(tr.Data) {
+			tr.Data = tr.Data[:n+1]
+		} else {
+			tr.Data = append(tr.Data, tradeData{})
+		}
+		d := &tr.Data[n]
+		*d = tradeData{Side: d.Side}
+
+		var side []byte
+		err := s.Object(func(key []byte) error {
+			var err error
+			switch string(key) {
+			case "id":
+				d.ID, err = s.Int()
+			case "liquidation":
+				d.Liquidation, err = s.Bool()
+			case "price":
+				d.Price, err = s.Float()
+			case "side":
+				side, err = s.Str()
+			case "size":
+				d.Size, err = s.Float()
+			case "time":
+				d.Time, err = s.Time()
+			default:
+				err = s.Skip()
+			}
+			return err
+		})
+		d.Side = intern(side, d.Side)
+		return err
+	})
+}
+
+func decodeTicker(s *jsonscan.Scanner, tk *Ticker) error {
+	d := &tk.Data
+	d.Bid, d.Ask, d.BidSize, d.AskSize, d.Last = 0, 0, 0, 0, 0
+
+	var hasTime bool
+	err := s.Object(func(key []byte) error {
+		var err error
+		switch string(key) {
+		case "bid":
+			d.Bid, err = s.Float()
+		case "ask":
+			d.Ask, err = s.Float()
+		case "bidSize":
+			d.BidSize, err = s.Float()
+		case "askSize":
+			d.AskSize, err = s.Float()
+		case "last":
+			d.Last, err = s.Float()
+		case "time":
+			if d.Time == nil {
+				d.Time = new(Time)
+			}
+			hasTime = true
+			err = decodeUnixTime(s, d.Time)
+		default:
+			err = s.Skip()
+		}
+		return err
+	})
+	if !hasTime {
+		d.Time = nil
+	}
+	return err
+}
+
+// levels parses [[price, size], ...] into dst, reusing its inner slices.
+func decodeLevels(s *jsonscan.Scanner, dst [][]float64) ([][]float64, error) {
+	dst = dst[:0]
+	err := s.Array(func() error {
+		var level []float64
+		if n := len(dst); n < cap(dst) {
+			level = dst[:n+1][n][:0]
+		}
+		var err error
+		level, err = decodeFloats(s, level)
+		dst = append(dst, level)
+		return err
+	})
+	return dst, err
+}
+
+func decodeFloats(s *jsonscan.Scanner, dst []float64) ([]float64, error) {
+	err := s.Array(func() error {
+		v, err := s.Float()
+		dst = append(dst, v)
+		return err
+	})
+	return dst, err
+}
+
+// intern returns b as a string without allocating if it equals prev or a
+// well-known value.
+func intern(b []byte, prev string) string {
+	if string(b) == prev {
+		return prev
+	}
+	switch string(b) {
+	case "":
+		return ""
+	case "partial":
+		return "partial"
+	case "update":
+		return "update"
+	case "subscribed":
+		return "subscribed"
+	case "buy":
+		return "buy"
+	case "sell":
+		return "sell"
+	case ChannelOrderBook:
+		return ChannelOrderBook
+	case ChannelTrades:
+		return ChannelTrades
+	case ChannelTicker:
+		return ChannelTicker
+	}
+	return string(b)
+}
+
+// unixTime parses seconds since the epoch like Time.UnmarshalJSON.
+func decodeUnixTime(s *jsonscan.Scanner, t *Time) error {
+	f, err := s.Float()
+	if err != nil {
+		return err
+	}
+	sec, nsec := math.Modf(f)
+	t.Time = time.Unix(int64(sec), int64(nsec))
+	return nil
+}
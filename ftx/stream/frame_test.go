@@ -0,0 +1,134 @@
+package stream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	orderBookFrame = `{"channel": "orderbook", "market": "BTC-PERP", "type": "update", "data": {"time": 1630454400.123456, "checksum": 3115290520, "bids": [[47091.0, 0.0], [47090.5, 1.2345]], "asks": [[47095.0, 0.5]], "action": "update"}}`
+	tradesFrame    = `{"channel": "trades", "market": "BTC-PERP", "type": "update", "data": [{"id": 1, "price": 47091.0, "size": 0.01, "side": "buy", "liquidation": false, "time": "2021-09-01T00:00:00.123456+00:00"}, {"id": 2, "price": 47090.5, "size": 0.2, "side": "sell", "liquidation": true, "time": "2021-09-01T08:00:00+08:00"}]}`
+	tickerFrame    = `{"channel": "ticker", "market": "BTC-PERP", "type": "update", "data": {"bid": 47091.0, "ask": 47095.0, "bidSize": 1.5, "askSize": 0.5, "last": null, "time": 1630454400.123456}}`
+)
+
+func TestDecodeFrame(t *testing.T) {
+	t.Run("orderbook", func(t *testing.T) {
+		var f Frame
+		assert.NoError(t, DecodeFrame([]byte(orderBookFrame), &f))
+
+		want, err := decode([]byte(orderBookFrame))
+		assert.NoError(t, err)
+		assert.Equal(t, want, f.OrderBook)
+		assert.Equal(t, "update", f.Type)
+		assert.Equal(t, ChannelOrderBook, f.Channel)
+		assert.Equal(t, "BTC-PERP", f.Market)
+	})
+
+	t.Run("trades", func(t *testing.T) {
+		var f Frame
+		assert.NoError(t, DecodeFrame([]byte(tradesFrame), &f))
+
+		want, err := decode([]byte(tradesFrame))
+		assert.NoError(t, err)
+		wantTrades := want.(Trade)
+		assert.Equal(t, wantTrades.General, f.Trade.General)
+		assert.Len(t, f.Trade.Data, 2)
+		for i := range wantTrades.Data {
+			w, got := wantTrades.Data[i], f.Trade.Data[i]
+			assert.True(t, w.Time.Equal(got.Time))
+			w.Time, got.Time = time.Time{}, time.Time{}
+			assert.Equal(t, w, got)
+		}
+		assert.Equal(t, time.UTC, f.Trade.Data[1].Time.Location())
+	})
+
+	t.Run("ticker", func(t *testing.T) {
+		var f Frame
+		assert.NoError(t, DecodeFrame([]byte(tickerFrame), &f))
+
+		want, err := decode([]byte(tickerFrame))
+		assert.NoError(t, err)
+		assert.Equal(t, want, f.Ticker)
+	})
+
+	t.Run("data before channel", func(t *testing.T) {
+		var f Frame
+		assert.NoError(t, DecodeFrame([]byte(`{"data": {"last": 1.5}, "type": "update", "market": "BTC-PERP", "channel": "ticker"}`), &f))
+
+		assert.Equal(t, 1.5, f.Ticker.Data.Last)
+		assert.Equal(t, "BTC-PERP", f.Ticker.Market)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var f Frame
+		assert.NoError(t, DecodeFrame([]byte(`{"type": "error", "code": 400, "msg": "Invalid \"market\""}`), &f))
+
+		assert.Equal(t, "error", f.Type)
+		assert.Equal(t, 400, f.Code)
+		assert.Equal(t, `Invalid "market"`, f.Msg)
+	})
+
+	t.Run("other channel", func(t *testing.T) {
+		var f Frame
+		assert.NoError(t, DecodeFrame([]byte(`{"type": "update", "channel": "fills", "data": {"id": 123, "extra": [{"a": null}, true]}}`), &f))
+
+		assert.IsType(t, Fills{}, f.Other)
+		assert.Equal(t, 123, f.Other.(Fills).Data.ID)
+	})
+
+	t.Run("reuse", func(t *testing.T) {
+		var f Frame
+		assert.NoError(t, DecodeFrame([]byte(orderBookFrame), &f))
+		assert.NoError(t, DecodeFrame([]byte(`{"channel": "orderbook", "market": "ETH-PERP", "type": "partial", "data": {"bids": [[1, 2]], "asks": []}}`), &f))
+
+		assert.Equal(t, "partial", f.Type)
+		assert.Equal(t, "ETH-PERP", f.OrderBook.Market)
+		assert.Equal(t, [][]float64{{1, 2}}, f.OrderBook.Data.Bids)
+		assert.Empty(t, f.OrderBook.Data.Asks)
+		assert.Equal(t, 0, f.OrderBook.Data.Checksum)
+		assert.Nil(t, f.OrderBook.Data.Time)
+	})
+
+	t.Run("reuse without levels", func(t *testing.T) {
+		var f Frame
+		assert.NoError(t, DecodeFrame([]byte(orderBookFrame), &f))
+		assert.NoError(t, DecodeFrame([]byte(`{"channel": "orderbook", "market": "BTC-PERP", "type": "update", "data": {"action": "update", "checksum": 1}}`), &f))
+
+		assert.Empty(t, f.OrderBook.Data.Bids)
+		assert.Empty(t, f.OrderBook.Data.Asks)
+		assert.Equal(t, 1, f.OrderBook.Data.Checksum)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var f Frame
+		assert.Error(t, DecodeFrame([]byte(`{"channel": "orderbook", "data": {"bids": [[1, 2}}`), &f))
+		assert.Error(t, DecodeFrame([]byte(`{"type": "update"`), &f))
+	})
+}
+
+func TestDecodeFrame_Allocs(t *testing.T) {
+	for name, frame := range map[string]string{"orderbook": orderBookFrame, "trades": tradesFrame, "ticker": tickerFrame} {
+		msg := []byte(frame)
+		var f Frame
+		assert.NoError(t, DecodeFrame(msg, &f))
+
+		allocs := testing.AllocsPerRun(100, func() { _ = DecodeFrame(msg, &f) })
+		assert.Zero(t, allocs, name)
+	}
+}
+
+func TestConn_RecvFrame(t *testing.T) {
+	conn, ws, teardown := setup()
+	defer teardown()
+
+	var f Frame
+	for _, frame := range []string{orderBookFrame, tickerFrame} {
+		assert.NoError(t, ws.WriteMessage(1, []byte(frame)))
+		assert.NoError(t, conn.RecvFrame(&f))
+	}
+
+	assert.Equal(t, ChannelTicker, f.Channel)
+	assert.Equal(t, 47091.0, f.Ticker.Data.Bid)
+}
@@ -0,0 +1,300 @@
+package stream
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrHubClosed is returned by Hub.Subscribe after the hub stopped receiving.
+var ErrHubClosed = errors.New("hub closed")
+
+// Hub multiplexes one Conn among many subscribers. The upstream subscription
+// of a channel and market is kept while anyone is subscribed to it.
+//
+// Orderbook subscribers start with a partial: a late subscriber makes the hub
+// resubscribe upstream, so that every subscriber of the market receives a new
+// partial. The same happens when an orderbook message is dropped, see
+// Subscription.
+//
+// The hub is the only reader of the Conn; don't call Recv on it yourself.
+type Hub struct {
+	conn   *Conn
+	buffer int
+
+	opMu sync.Mutex // serializes upstream requests, which are sent without mu
+
+	mu        sync.Mutex
+	subs      map[feedKey]map[*Subscription]struct{}
+	resyncing map[feedKey]bool
+	err       error
+	done      chan struct{}
+}
+
+// Subscription receives the messages of one channel and market, and errors
+// received by the hub, on C. C is closed by Unsubscribe or when the hub stops.
+// A message of the subscription that can't be decoded is sent as its decode
+// error.
+//
+// Messages are dropped while C is full. After a dropped or undecodable
+// orderbook message the subscription skips updates until the partial of a
+// resubscription.
+type Subscription struct {
+	C <-chan interface{}
+
+	hub     *Hub
+	key     feedKey
+	c       chan interface{}
+	dropped int64
+	synced  bool // guarded by hub.mu
+}
+
+// NewHub starts receiving from conn. Each subscription buffers up to buffer messages.
+func NewHub(conn *Conn, buffer int) *Hub {
+	h := &Hub{
+		conn:      conn,
+		buffer:    buffer,
+		subs:      make(map[feedKey]map[*Subscription]struct{}),
+		resyncing: make(map[feedKey]bool),
+		done:      make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+// Subscribe subscribes to channel and market, subscribing upstream if nobody
+// was subscribed to it yet.
+func (h *Hub) Subscribe(channel string, market ...string) (*Subscription, error) {
+	k := feedKey{channel: channel}
+	if len(market) >= 1 {
+		k.market = market[0]
+	}
+
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.mu.Unlock()
+		return nil, ErrHubClosed
+	}
+	subs, ok := h.subs[k]
+	if !ok {
+		subs = make(map[*Subscription]struct{})
+		h.subs[k] = subs
+	}
+	c := make(chan interface{}, h.buffer)
+	s := &Subscription{C: c, hub: h, key: k, c: c, synced: k.channel != ChannelOrderBook}
+	subs[s] = struct{}{}
+	h.mu.Unlock()
+
+	// The subscription is registered before the request is sent, so it
+	// receives the first messages of the upstream subscription.
+	var err error
+	switch {
+	case !ok:
+		err = subscribe(h.conn, k)
+	case k.channel == ChannelOrderBook:
+		err = resubscribe(h.conn, k)
+	}
+	if err != nil {
+		h.mu.Lock()
+		h.remove(s)
+		h.mu.Unlock()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Unsubscribe closes C, unsubscribing upstream if s was the last subscriber.
+func (s *Subscription) Unsubscribe() error {
+	h := s.hub
+
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	removed, last := h.remove(s)
+	h.mu.Unlock()
+
+	if !removed || !last {
+		return nil
+	}
+	return unsubscribe(h.conn, s.key)
+}
+
+// remove removes s and closes C. It reports whether s was subscribed and
+// whether it was the last subscriber. mu must be held.
+func (h *Hub) remove(s *Subscription) (removed, last bool) {
+	subs, ok := h.subs[s.key]
+	if _, subscribed := subs[s]; !ok || !subscribed {
+		return false, false
+	}
+	delete(subs, s)
+	close(s.c)
+	if len(subs) > 0 {
+		return true, false
+	}
+	delete(h.subs, s.key)
+	return true, true
+}
+
+// resync resubscribes to k upstream for the subscribers that dropped an
+// orderbook message.
+func (h *Hub) resync(k feedKey) {
+	h.opMu.Lock()
+	defer h.opMu.Unlock()
+
+	h.mu.Lock()
+	delete(h.resyncing, k)
+	_, ok := h.subs[k]
+	h.mu.Unlock()
+
+	if ok {
+		// An error stops the hub, which closes the subscriptions.
+		_ = resubscribe(h.conn, k)
+	}
+}
+
+// Dropped returns how many messages were dropped because C was full.
+func (s *Subscription) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Done is closed when the hub stopped receiving, see Err.
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// Err returns the error that stopped the hub.
+func (h *Hub) Err() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.err
+}
+
+// Close closes the underlying Conn, which stops the hub.
+func (h *Hub) Close() error {
+	return h.conn.Close()
+}
+
+func (h *Hub) run() {
+	for {
+		msg, err := h.conn.RecvRaw()
+		if err != nil {
+			h.stop(err)
+			return
+		}
+		// Only read errors stop the hub, a message that can't be decoded
+		// doesn't.
+		v, err := decode(msg)
+		h.dispatch(v, err)
+	}
+}
+
+func (h *Hub) dispatch(msg interface{}, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err != nil {
+		// Messages of unknown channels are skipped.
+		k, ok := keyOf(msg)
+		if !ok {
+			return
+		}
+		for s := range h.subs[k] {
+			s.send(err)
+			if k.channel == ChannelOrderBook && s.synced {
+				s.synced = false
+				h.startResync(k)
+			}
+		}
+		return
+	}
+
+	switch v := msg.(type) {
+	case Error:
+		for _, subs := range h.subs {
+			for s := range subs {
+				s.send(msg)
+			}
+		}
+		return
+	case General:
+		// Unsubscribed responses answer resubscriptions of the hub.
+		if v.Type == "unsubscribed" {
+			return
+		}
+	}
+
+	k, ok := keyOf(msg)
+	if !ok {
+		return
+	}
+	ob, isBook := msg.(OrderBook)
+	for s := range h.subs[k] {
+		if !isBook {
+			s.send(msg)
+			continue
+		}
+		if ob.Type == "partial" {
+			s.synced = true
+		}
+		if s.synced && !s.send(msg) {
+			s.synced = false
+			h.startResync(k)
+		}
+	}
+}
+
+// startResync starts resyncing k unless it is already. mu must be held.
+func (h *Hub) startResync(k feedKey) {
+	if h.subs != nil && !h.resyncing[k] {
+		h.resyncing[k] = true
+		go h.resync(k)
+	}
+}
+
+// send sends msg on C and reports whether it wasn't dropped.
+func (s *Subscription) send(msg interface{}) bool {
+	select {
+	case s.c <- msg:
+		return true
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+		return false
+	}
+}
+
+func (h *Hub) stop(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subs {
+		for s := range subs {
+			close(s.c)
+		}
+	}
+	h.subs = nil
+	h.err = err
+	close(h.done)
+}
+
+func keyOf(msg interface{}) (feedKey, bool) {
+	switch v := msg.(type) {
+	case General:
+		return feedKey{v.Channel, v.Market}, true
+	case OrderBook:
+		return feedKey{v.Channel, v.Market}, true
+	case Trade:
+		return feedKey{v.Channel, v.Market}, true
+	case Ticker:
+		return feedKey{v.Channel, v.Market}, true
+	case Fills:
+		return feedKey{channel: v.Channel}, true
+	case Orders:
+		return feedKey{channel: v.Channel}, true
+	default:
+		return feedKey{}, false
+	}
+}
@@ -0,0 +1,211 @@
+package stream
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupPush serves a websocket forwarding the requests it receives to
+// requests and sending the frames written to push.
+func setupPush() (conn *Conn, requests <-chan connRequest, push chan<- connResponse, teardown func()) {
+	reqs := make(chan connRequest, 16)
+	frames := make(chan connResponse, 16)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		go func() {
+			for f := range frames {
+				if err := ws.WriteJSON(f); err != nil {
+					return
+				}
+			}
+		}()
+		for {
+			var req connRequest
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+			reqs <- req
+		}
+	}))
+	u, _ := url.Parse(srv.URL)
+	u.Scheme = "ws"
+
+	ws, _, _ := websocket.DefaultDialer.Dial(u.String(), nil)
+	conn = New(ws, "", nil, "")
+
+	return conn, reqs, frames, func() {
+		_ = ws.Close()
+		srv.Close()
+	}
+}
+
+func recvTimeout(t *testing.T, c <-chan interface{}) interface{} {
+	t.Helper()
+	select {
+	case msg := <-c:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("timeout")
+		return nil
+	}
+}
+
+func TestHub(t *testing.T) {
+	conn, requests, push, teardown := setupPush()
+	defer teardown()
+
+	hub := NewHub(conn, 4)
+
+	a, err := hub.Subscribe(ChannelTicker, "BTC-PERP")
+	assert.NoError(t, err)
+	b, err := hub.Subscribe(ChannelTicker, "BTC-PERP")
+	assert.NoError(t, err)
+	eth, err := hub.Subscribe(ChannelTicker, "ETH-PERP")
+	assert.NoError(t, err)
+
+	assert.Equal(t, connRequest{OP: "subscribe", Channel: ChannelTicker, Market: "BTC-PERP"}, <-requests)
+	assert.Equal(t, connRequest{OP: "subscribe", Channel: ChannelTicker, Market: "ETH-PERP"}, <-requests)
+
+	push <- connResponse{Type: "update", Channel: ChannelTicker, Market: "BTC-PERP", Data: []byte(`{"bid":1}`)}
+	push <- connResponse{Type: "update", Channel: ChannelTicker, Market: "ETH-PERP", Data: []byte(`{"bid":2}`)}
+
+	assert.Equal(t, 1.0, recvTimeout(t, a.C).(Ticker).Data.Bid)
+	assert.Equal(t, 1.0, recvTimeout(t, b.C).(Ticker).Data.Bid)
+	assert.Equal(t, 2.0, recvTimeout(t, eth.C).(Ticker).Data.Bid)
+
+	// The upstream subscription stays while b uses it.
+	assert.NoError(t, a.Unsubscribe())
+	_, ok := <-a.C
+	assert.False(t, ok)
+	assert.NoError(t, a.Unsubscribe())
+
+	push <- connResponse{Type: "error", Code: 400, Msg: "something wrong"}
+	assert.IsType(t, Error{}, recvTimeout(t, b.C))
+	assert.IsType(t, Error{}, recvTimeout(t, eth.C))
+
+	assert.NoError(t, b.Unsubscribe())
+	assert.Equal(t, connRequest{OP: "unsubscribe", Channel: ChannelTicker, Market: "BTC-PERP"}, <-requests)
+
+	// Stopping the hub closes the remaining subscriptions.
+	assert.NoError(t, hub.Close())
+	<-hub.Done()
+	_, ok = <-eth.C
+	assert.False(t, ok)
+	assert.Error(t, hub.Err())
+
+	_, err = hub.Subscribe(ChannelTicker, "BTC-PERP")
+	assert.Equal(t, ErrHubClosed, err)
+}
+
+func TestHub_Dropped(t *testing.T) {
+	conn, requests, push, teardown := setupPush()
+	defer teardown()
+
+	hub := NewHub(conn, 1)
+
+	slow, err := hub.Subscribe(ChannelFills)
+	assert.NoError(t, err)
+	fast, err := hub.Subscribe(ChannelFills)
+	assert.NoError(t, err)
+	<-requests
+
+	for i := 0; i < 3; i++ {
+		push <- connResponse{Type: "update", Channel: ChannelFills, Data: []byte(`{"id":1}`)}
+		recvTimeout(t, fast.C)
+	}
+
+	assert.Eventually(t, func() bool { return slow.Dropped() == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(0), fast.Dropped())
+}
+
+func TestHub_OrderBookLateSubscriber(t *testing.T) {
+	conn, requests, push, teardown := setupPush()
+	defer teardown()
+
+	hub := NewHub(conn, 4)
+	book := func(typ string) connResponse {
+		return connResponse{Type: typ, Channel: ChannelOrderBook, Market: "BTC-PERP", Data: []byte(`{"action":"` + typ + `"}`)}
+	}
+
+	early, err := hub.Subscribe(ChannelOrderBook, "BTC-PERP")
+	assert.NoError(t, err)
+	assert.Equal(t, connRequest{OP: "subscribe", Channel: ChannelOrderBook, Market: "BTC-PERP"}, <-requests)
+	push <- book("partial")
+	assert.Equal(t, "partial", recvTimeout(t, early.C).(OrderBook).Type)
+
+	// A late subscriber resubscribes upstream to receive a partial.
+	late, err := hub.Subscribe(ChannelOrderBook, "BTC-PERP")
+	assert.NoError(t, err)
+	assert.Equal(t, connRequest{OP: "unsubscribe", Channel: ChannelOrderBook, Market: "BTC-PERP"}, <-requests)
+	assert.Equal(t, connRequest{OP: "subscribe", Channel: ChannelOrderBook, Market: "BTC-PERP"}, <-requests)
+
+	push <- book("update")
+	push <- connResponse{Type: "unsubscribed", Channel: ChannelOrderBook, Market: "BTC-PERP"}
+	push <- book("partial")
+
+	assert.Equal(t, "update", recvTimeout(t, early.C).(OrderBook).Type)
+	assert.Equal(t, "partial", recvTimeout(t, early.C).(OrderBook).Type)
+	assert.Equal(t, "partial", recvTimeout(t, late.C).(OrderBook).Type, "updates before the partial are skipped")
+}
+
+func TestHub_OrderBookDropped(t *testing.T) {
+	conn, requests, push, teardown := setupPush()
+	defer teardown()
+
+	hub := NewHub(conn, 1)
+	book := func(typ string) connResponse {
+		return connResponse{Type: typ, Channel: ChannelOrderBook, Market: "BTC-PERP", Data: []byte(`{"action":"` + typ + `"}`)}
+	}
+
+	s, err := hub.Subscribe(ChannelOrderBook, "BTC-PERP")
+	assert.NoError(t, err)
+	<-requests
+
+	push <- book("partial")
+	push <- book("update")
+
+	// The dropped update makes the hub resubscribe.
+	assert.Equal(t, connRequest{OP: "unsubscribe", Channel: ChannelOrderBook, Market: "BTC-PERP"}, <-requests)
+	assert.Equal(t, connRequest{OP: "subscribe", Channel: ChannelOrderBook, Market: "BTC-PERP"}, <-requests)
+	assert.Equal(t, int64(1), s.Dropped())
+	assert.Equal(t, "partial", recvTimeout(t, s.C).(OrderBook).Type)
+
+	push <- book("update")
+	push <- book("partial")
+	assert.Equal(t, "partial", recvTimeout(t, s.C).(OrderBook).Type, "updates are skipped until the partial")
+}
+
+func TestHub_DecodeError(t *testing.T) {
+	conn, requests, push, teardown := setupPush()
+	defer teardown()
+
+	hub := NewHub(conn, 4)
+
+	s, err := hub.Subscribe(ChannelTicker, "BTC-PERP")
+	assert.NoError(t, err)
+	<-requests
+
+	push <- connResponse{Type: "update", Channel: "markets", Data: []byte(`{}`)}
+	push <- connResponse{Type: "update", Channel: ChannelTicker, Market: "BTC-PERP", Data: []byte(`{"bid":"x"}`)}
+	push <- connResponse{Type: "update", Channel: ChannelTicker, Market: "BTC-PERP", Data: []byte(`{"bid":1}`)}
+
+	assert.Implements(t, (*error)(nil), recvTimeout(t, s.C))
+	assert.Equal(t, 1.0, recvTimeout(t, s.C).(Ticker).Data.Bid)
+
+	select {
+	case <-hub.Done():
+		t.Fatal("hub stopped")
+	default:
+	}
+}
@@ -0,0 +1,510 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned by Pool.Recv after Close.
+var ErrPoolClosed = errors.New("pool closed")
+
+// Pool spreads subscriptions across several connections and merges what they
+// receive into one Recv.
+//
+// Subscriptions are placed on the connection with the fewest of them. When a
+// connection fails to receive, e.g. because it was closed by a watchdog, its
+// subscriptions are placed again on the other connections right away, and it
+// is replaced by a new one from dial, retried with backoff. Once the new
+// connection is open, subscriptions are moved to it from the most loaded
+// connections.
+//
+// A moved subscription is subscribed on its new connection only once the old
+// one acknowledged the unsubscription, and messages of the old connection for
+// it are dropped, so a moved orderbook subscription starts with a new partial.
+type Pool struct {
+	dial       func() (*Conn, error)
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	out        chan poolEvent
+	done       chan struct{}
+
+	opMu sync.Mutex // serializes subscription requests, which are sent without mu
+
+	mu     sync.Mutex
+	conns  []*pooledConn
+	subs   map[feedKey]*pooledConn // nil while waiting for a connection
+	moving map[feedKey]*pooledConn // connection being unsubscribed from
+	closed bool
+}
+
+// Backoff between attempts to replace a failed connection.
+var (
+	minRedialBackoff = 100 * time.Millisecond
+	maxRedialBackoff = 30 * time.Second
+)
+
+type pooledConn struct {
+	conn *Conn
+	subs map[feedKey]struct{}
+}
+
+type poolEvent struct {
+	msg interface{}
+	err error
+}
+
+// poolOp is a subscription request decided under mu and sent without it.
+type poolOp struct {
+	pc        *pooledConn
+	key       feedKey
+	subscribe bool
+}
+
+// NewPool opens n connections with dial, e.g. ftx.Client.Connect. The pool
+// doesn't log in: to subscribe to private channels, dial must return logged-in
+// connections, e.g. by calling LoginWait after ftx.Client.Connect.
+func NewPool(n int, dial func() (*Conn, error)) (*Pool, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("invalid number of connections: %d", n)
+	}
+	p := &Pool{
+		dial:       dial,
+		minBackoff: minRedialBackoff,
+		maxBackoff: maxRedialBackoff,
+		out:        make(chan poolEvent, 64*n),
+		done:       make(chan struct{}),
+		subs:       make(map[feedKey]*pooledConn),
+		moving:     make(map[feedKey]*pooledConn),
+	}
+	conns := make([]*Conn, 0, n)
+	for i := 0; i < n; i++ {
+		conn, err := dial()
+		if err != nil {
+			for _, c := range conns {
+				_ = c.Close()
+			}
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+
+	// A connection failing right away recycles itself, so the others must be
+	// added before it.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range conns {
+		p.add(conn)
+	}
+	return p, nil
+}
+
+// add starts receiving from conn. p.mu must be held.
+func (p *Pool) add(conn *Conn) {
+	pc := &pooledConn{conn: conn, subs: make(map[feedKey]struct{})}
+	p.conns = append(p.conns, pc)
+	go p.run(pc)
+}
+
+func (p *Pool) run(pc *pooledConn) {
+	for {
+		msg, err := pc.conn.RecvRaw()
+		if err != nil {
+			p.recycle(pc, err)
+			return
+		}
+		v, err := decode(msg)
+		if !p.owns(pc, v) {
+			continue
+		}
+		select {
+		case p.out <- poolEvent{msg: v, err: err}:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// owns reports whether msg received by pc is passed to Recv: messages of a
+// subscription placed on another connection are dropped. The unsubscribed
+// response ending a move lets the new connection subscribe.
+func (p *Pool) owns(pc *pooledConn, msg interface{}) bool {
+	k, ok := keyOf(msg)
+	if !ok {
+		return true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if g, ok := msg.(General); ok && g.Type == "unsubscribed" && p.moving[k] == pc {
+		go p.moved(k, pc)
+	}
+	owner, ok := p.subs[k]
+	return !ok || owner == pc
+}
+
+// Subscribe subscribes on the least loaded connection. Subscribing twice to
+// the same channel and market is a no-op.
+func (p *Pool) Subscribe(channel string, market ...string) error {
+	k := feedKey{channel: channel}
+	if len(market) >= 1 {
+		k.market = market[0]
+	}
+
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+	if _, ok := p.subs[k]; ok {
+		p.mu.Unlock()
+		return nil
+	}
+	pc := p.place(k)
+	p.mu.Unlock()
+
+	if pc == nil {
+		return nil
+	}
+	if err := subscribe(pc.conn, k); err != nil {
+		p.mu.Lock()
+		if owner, ok := p.subs[k]; ok && (owner == pc || owner == nil) {
+			delete(p.subs, k)
+			delete(pc.subs, k)
+		}
+		p.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// place assigns k to the least loaded connection and returns it, or keeps k
+// for the next connection and returns nil if there is none. p.mu must be held.
+func (p *Pool) place(k feedKey) *pooledConn {
+	if len(p.conns) == 0 {
+		p.subs[k] = nil
+		return nil
+	}
+
+	pc := p.conns[0]
+	for _, c := range p.conns[1:] {
+		if len(c.subs) < len(pc.subs) {
+			pc = c
+		}
+	}
+	pc.subs[k] = struct{}{}
+	p.subs[k] = pc
+	return pc
+}
+
+func (p *Pool) Unsubscribe(channel string, market ...string) error {
+	k := feedKey{channel: channel}
+	if len(market) >= 1 {
+		k.market = market[0]
+	}
+
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.Lock()
+	pc, ok := p.subs[k]
+	if !ok {
+		p.mu.Unlock()
+		return nil
+	}
+	delete(p.subs, k)
+	if pc != nil {
+		delete(pc.subs, k)
+	}
+	if _, ok := p.moving[k]; ok {
+		// Already unsubscribed from the old connection, and not subscribed
+		// on the new one yet.
+		delete(p.moving, k)
+		pc = nil
+	}
+	p.mu.Unlock()
+
+	if pc == nil {
+		return nil
+	}
+	return unsubscribe(pc.conn, k)
+}
+
+// recycle places the subscriptions of pc on the other connections after it
+// failed, and replaces it.
+func (p *Pool) recycle(pc *pooledConn, err error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	for i, c := range p.conns {
+		if c == pc {
+			p.conns = append(p.conns[:i], p.conns[i+1:]...)
+			break
+		}
+	}
+	for k := range pc.subs {
+		p.subs[k] = nil
+	}
+	if len(p.conns) == 0 {
+		p.report(err)
+	}
+	p.mu.Unlock()
+
+	// Requests sent to pc fail from now on instead of holding up others.
+	_ = pc.conn.Close()
+
+	p.opMu.Lock()
+	p.mu.Lock()
+	// Moves from pc are over: it won't receive anything anymore.
+	for k, from := range p.moving {
+		if from == pc {
+			delete(p.moving, k)
+		}
+	}
+	ops := p.restore(nil)
+	p.mu.Unlock()
+	p.send(ops)
+	p.opMu.Unlock()
+
+	conn, ok := p.redial()
+	if !ok {
+		return
+	}
+
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		_ = conn.Close()
+		return
+	}
+	p.add(conn)
+	ops = p.restore(nil)
+	ops = p.rebalance(ops)
+	p.mu.Unlock()
+	p.send(ops)
+}
+
+// moved subscribes k on its new connection after it was unsubscribed from.
+func (p *Pool) moved(k feedKey, from *pooledConn) {
+	p.opMu.Lock()
+	defer p.opMu.Unlock()
+
+	p.mu.Lock()
+	if p.closed || p.moving[k] != from {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.moving, k)
+	var ops []poolOp
+	if pc := p.subs[k]; pc != nil {
+		ops = append(ops, poolOp{pc: pc, key: k, subscribe: true})
+	} else {
+		ops = p.restore(ops)
+	}
+	p.mu.Unlock()
+	p.send(ops)
+}
+
+// send sends ops in order. A subscription that fails waits for the next
+// connection. p.opMu must be held.
+func (p *Pool) send(ops []poolOp) {
+	for _, op := range ops {
+		if !op.subscribe {
+			if err := unsubscribe(op.pc.conn, op.key); err != nil {
+				p.report(err)
+			}
+			continue
+		}
+		if err := subscribe(op.pc.conn, op.key); err != nil {
+			p.mu.Lock()
+			if p.subs[op.key] == op.pc {
+				delete(op.pc.subs, op.key)
+				p.subs[op.key] = nil
+			}
+			p.mu.Unlock()
+			p.report(err)
+		}
+	}
+}
+
+// redial dials until it succeeds or the pool is closed, doubling the wait
+// between attempts. Dial errors are passed to Recv.
+func (p *Pool) redial() (*Conn, bool) {
+	wait := p.minBackoff
+	for {
+		conn, err := p.dial()
+		if err == nil {
+			return conn, true
+		}
+		p.report(err)
+
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-p.done:
+			t.Stop()
+			return nil, false
+		}
+		if wait *= 2; wait > p.maxBackoff {
+			wait = p.maxBackoff
+		}
+	}
+}
+
+// restore places the subscriptions waiting for a connection, except those
+// still being unsubscribed from their old one, and appends the requests to
+// ops. p.mu must be held.
+func (p *Pool) restore(ops []poolOp) []poolOp {
+	var keys []feedKey
+	for k, pc := range p.subs {
+		if _, moving := p.moving[k]; pc == nil && !moving {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range sortKeys(keys) {
+		if pc := p.place(k); pc != nil {
+			ops = append(ops, poolOp{pc: pc, key: k, subscribe: true})
+		}
+	}
+	return ops
+}
+
+// rebalance moves subscriptions from the most loaded connections to the least
+// loaded one until their loads differ by at most one, and appends the
+// unsubscriptions to ops. The subscriptions on the least loaded connection
+// are sent by moved. p.mu must be held.
+func (p *Pool) rebalance(ops []poolOp) []poolOp {
+	for len(p.conns) > 1 {
+		least, most := p.conns[0], p.conns[0]
+		for _, c := range p.conns[1:] {
+			if len(c.subs) < len(least.subs) {
+				least = c
+			}
+			if len(c.subs) > len(most.subs) {
+				most = c
+			}
+		}
+		if len(most.subs)-len(least.subs) <= 1 {
+			return ops
+		}
+
+		// Subscriptions not subscribed on most yet can't be moved.
+		keys := make([]feedKey, 0, len(most.subs))
+		for k := range most.subs {
+			if _, moving := p.moving[k]; !moving {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) == 0 {
+			return ops
+		}
+		k := sortKeys(keys)[0]
+
+		delete(most.subs, k)
+		least.subs[k] = struct{}{}
+		p.subs[k] = least
+		p.moving[k] = most
+		ops = append(ops, poolOp{pc: most, key: k})
+	}
+	return ops
+}
+
+// sortKeys sorts keys by channel and market, so that they are placed in a
+// stable order.
+func sortKeys(keys []feedKey) []feedKey {
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].channel != keys[j].channel {
+			return keys[i].channel < keys[j].channel
+		}
+		return keys[i].market < keys[j].market
+	})
+	return keys
+}
+
+// report passes err to Recv without blocking the caller holding p.mu.
+func (p *Pool) report(err error) {
+	go func() {
+		select {
+		case p.out <- poolEvent{err: err}:
+		case <-p.done:
+		}
+	}()
+}
+
+// Recv returns the next message received by any connection. Errors of the
+// last open connection and of failed attempts to replace a connection are
+// returned as well.
+func (p *Pool) Recv() (interface{}, error) {
+	select {
+	case <-p.done:
+		return nil, ErrPoolClosed
+	default:
+	}
+	select {
+	case e := <-p.out:
+		return e.msg, e.err
+	case <-p.done:
+		return nil, ErrPoolClosed
+	}
+}
+
+// Len returns the number of open connections.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// Close closes all connections.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+
+	var err error
+	for _, pc := range p.conns {
+		if cerr := pc.conn.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func subscribe(conn *Conn, k feedKey) error {
+	if k.market != "" {
+		return conn.Subscribe(k.channel, k.market)
+	}
+	return conn.Subscribe(k.channel)
+}
+
+// resubscribe unsubscribes from k and subscribes again, e.g. to receive a new
+// orderbook partial.
+func resubscribe(conn *Conn, k feedKey) error {
+	if err := unsubscribe(conn, k); err != nil {
+		return err
+	}
+	return subscribe(conn, k)
+}
+
+func unsubscribe(conn *Conn, k feedKey) error {
+	if k.market != "" {
+		return conn.Unsubscribe(k.channel, k.market)
+	}
+	return conn.Unsubscribe(k.channel)
+}
@@ -0,0 +1,389 @@
+package stream
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// exchange serves websockets recording the markets subscribed on each of them
+// and acknowledging the requests.
+type exchange struct {
+	srv *httptest.Server
+
+	mu      sync.Mutex
+	conns   []*websocket.Conn
+	markets []map[string]bool
+	noAck   bool // don't acknowledge unsubscriptions
+}
+
+func newExchange() *exchange {
+	e := &exchange{}
+	e.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		e.mu.Lock()
+		i := len(e.conns)
+		e.conns = append(e.conns, ws)
+		e.markets = append(e.markets, make(map[string]bool))
+		e.mu.Unlock()
+
+		for {
+			var req connRequest
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+			e.mu.Lock()
+			e.markets[i][req.Market] = req.OP == "subscribe"
+			if req.OP == "subscribe" || !e.noAck {
+				_ = ws.WriteJSON(General{Type: req.OP + "d", Channel: req.Channel, Market: req.Market})
+			}
+			e.mu.Unlock()
+		}
+	}))
+	return e
+}
+
+func (e *exchange) dial() (*Conn, error) {
+	u, _ := url.Parse(e.srv.URL)
+	u.Scheme = "ws"
+
+	ws, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return New(ws, "", nil, ""), nil
+}
+
+// subscribed returns the markets subscribed on the i-th connection.
+func (e *exchange) subscribed(i int) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var out []string
+	if i < len(e.markets) {
+		for m, ok := range e.markets[i] {
+			if ok {
+				out = append(out, m)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (e *exchange) write(i int, v interface{}) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.conns[i].WriteJSON(v)
+}
+
+// recvData returns the next message of pool that isn't a response.
+func recvData(pool *Pool) (interface{}, error) {
+	for {
+		msg, err := pool.Recv()
+		if _, ok := msg.(General); !ok || err != nil {
+			return msg, err
+		}
+	}
+}
+
+// recvErr returns the next error of pool.
+func recvErr(pool *Pool) error {
+	for {
+		if _, err := pool.Recv(); err != nil {
+			return err
+		}
+	}
+}
+
+func TestPool(t *testing.T) {
+	e := newExchange()
+	defer e.srv.Close()
+
+	pool, err := NewPool(2, e.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	for _, m := range []string{"A", "B", "C", "D"} {
+		assert.NoError(t, pool.Subscribe(ChannelTicker, m))
+	}
+	assert.NoError(t, pool.Subscribe(ChannelTicker, "A"))
+	assert.NoError(t, pool.Unsubscribe(ChannelTicker, "D"))
+
+	// The server side order of the connections is not known.
+	var ac, b int
+	assert.Eventually(t, func() bool {
+		for i := 0; i < 2; i++ {
+			switch got := e.subscribed(i); {
+			case assert.ObjectsAreEqual([]string{"A", "C"}, got):
+				ac = i
+			case assert.ObjectsAreEqual([]string{"B"}, got):
+				b = i
+			default:
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+
+	// Messages of all connections are merged.
+	assert.NoError(t, e.write(ac, connResponse{Type: "update", Channel: ChannelTicker, Market: "A", Data: []byte(`{}`)}))
+	msg, err := recvData(pool)
+	assert.NoError(t, err)
+	assert.Equal(t, "A", msg.(Ticker).Market)
+
+	assert.NoError(t, e.write(b, connResponse{Type: "update", Channel: ChannelTicker, Market: "B", Data: []byte(`{}`)}))
+	msg, err = recvData(pool)
+	assert.NoError(t, err)
+	assert.Equal(t, "B", msg.(Ticker).Market)
+
+	// The subscriptions of a failed connection move to the other one, which
+	// hands A over to the replacement to balance the load.
+	e.mu.Lock()
+	_ = e.conns[ac].Close()
+	e.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"A"}, e.subscribed(2)) &&
+			assert.ObjectsAreEqual([]string{"B", "C"}, e.subscribed(b))
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 2, pool.Len())
+
+	assert.NoError(t, pool.Close())
+	_, err = pool.Recv()
+	assert.Equal(t, ErrPoolClosed, err)
+}
+
+func TestPool_InvalidSize(t *testing.T) {
+	pool, err := NewPool(0, func() (*Conn, error) {
+		t.Fatal("dialed")
+		return nil, nil
+	})
+	assert.Nil(t, pool)
+	assert.EqualError(t, err, "invalid number of connections: 0")
+}
+
+func TestPool_DialError(t *testing.T) {
+	e := newExchange()
+
+	pool, err := NewPool(1, e.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	assert.NoError(t, pool.Subscribe(ChannelTicker, "A"))
+	assert.Eventually(t, func() bool { return len(e.subscribed(0)) == 1 }, time.Second, time.Millisecond)
+
+	// Nothing is left to receive from once the exchange is gone.
+	e.srv.Close()
+	e.mu.Lock()
+	_ = e.conns[0].Close()
+	e.mu.Unlock()
+
+	assert.Error(t, recvErr(pool))
+	assert.Equal(t, 0, pool.Len())
+}
+
+func TestPool_Redial(t *testing.T) {
+	defer func(min, max time.Duration) { minRedialBackoff, maxRedialBackoff = min, max }(minRedialBackoff, maxRedialBackoff)
+	minRedialBackoff, maxRedialBackoff = time.Millisecond, 4*time.Millisecond
+
+	e := newExchange()
+	defer e.srv.Close()
+
+	var mu sync.Mutex
+	failures := 0
+	dial := func() (*Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			return nil, errors.New("dial failed")
+		}
+		return e.dial()
+	}
+
+	pool, err := NewPool(1, dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	assert.NoError(t, pool.Subscribe(ChannelTicker, "A"))
+	assert.NoError(t, pool.Subscribe(ChannelTicker, "B"))
+	assert.Eventually(t, func() bool { return len(e.subscribed(0)) == 2 }, time.Second, time.Millisecond)
+
+	mu.Lock()
+	failures = 2
+	mu.Unlock()
+	e.mu.Lock()
+	_ = e.conns[0].Close()
+	e.mu.Unlock()
+
+	// The error of the connection, then of the failed dials.
+	for i := 0; i < 3; i++ {
+		assert.Error(t, recvErr(pool))
+	}
+
+	// Subscriptions made meanwhile wait for the new connection as well.
+	assert.NoError(t, pool.Subscribe(ChannelTicker, "C"))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"A", "B", "C"}, e.subscribed(1)) && pool.Len() == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestPool_RestoreOnSurvivors(t *testing.T) {
+	defer func(min, max time.Duration) { minRedialBackoff, maxRedialBackoff = min, max }(minRedialBackoff, maxRedialBackoff)
+	minRedialBackoff, maxRedialBackoff = time.Millisecond, 4*time.Millisecond
+
+	e := newExchange()
+	defer e.srv.Close()
+
+	var mu sync.Mutex
+	down := false
+	dial := func() (*Conn, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			return nil, errors.New("dial failed")
+		}
+		return e.dial()
+	}
+
+	pool, err := NewPool(2, dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+	go func() {
+		for {
+			if _, err := pool.Recv(); err == ErrPoolClosed {
+				return
+			}
+		}
+	}()
+
+	for _, m := range []string{"A", "B", "C", "D"} {
+		assert.NoError(t, pool.Subscribe(ChannelTicker, m))
+	}
+	failed, survivor := 0, 1
+	assert.Eventually(t, func() bool {
+		return len(e.subscribed(0)) == 2 && len(e.subscribed(1)) == 2
+	}, time.Second, time.Millisecond)
+	if e.subscribed(1)[0] == "A" {
+		failed, survivor = 1, 0
+	}
+
+	mu.Lock()
+	down = true
+	mu.Unlock()
+	e.mu.Lock()
+	_ = e.conns[failed].Close()
+	e.mu.Unlock()
+
+	// The subscriptions move to the other connection while no new one opens.
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"A", "B", "C", "D"}, e.subscribed(survivor))
+	}, time.Second, time.Millisecond)
+
+	// The new connection takes over part of them.
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"A", "B"}, e.subscribed(2)) &&
+			assert.ObjectsAreEqual([]string{"C", "D"}, e.subscribed(survivor))
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 2, pool.Len())
+}
+
+func TestPool_FailOnStart(t *testing.T) {
+	defer func(min, max time.Duration) { minRedialBackoff, maxRedialBackoff = min, max }(minRedialBackoff, maxRedialBackoff)
+	minRedialBackoff, maxRedialBackoff = time.Millisecond, 4*time.Millisecond
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = ws.Close()
+	}))
+	defer srv.Close()
+
+	var dials int32
+	dial := func() (*Conn, error) {
+		atomic.AddInt32(&dials, 1)
+		u, _ := url.Parse(srv.URL)
+		u.Scheme = "ws"
+
+		ws, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return New(ws, "", nil, ""), nil
+	}
+
+	// Connections recycle themselves while the others are still being added.
+	pool, err := NewPool(8, dial)
+	assert.NoError(t, err)
+	assert.NoError(t, pool.Subscribe(ChannelTicker, "A"))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&dials) > 16 }, time.Second, time.Millisecond)
+	assert.NoError(t, pool.Close())
+}
+
+func TestPool_MoveAfterUnsubscribed(t *testing.T) {
+	defer func(min, max time.Duration) { minRedialBackoff, maxRedialBackoff = min, max }(minRedialBackoff, maxRedialBackoff)
+	minRedialBackoff, maxRedialBackoff = time.Millisecond, 4*time.Millisecond
+
+	e := newExchange()
+	defer e.srv.Close()
+
+	pool, err := NewPool(2, e.dial)
+	assert.NoError(t, err)
+	defer pool.Close()
+
+	for _, m := range []string{"A", "B", "C", "D"} {
+		assert.NoError(t, pool.Subscribe(ChannelOrderBook, m))
+	}
+	failed, survivor := 0, 1
+	assert.Eventually(t, func() bool {
+		return len(e.subscribed(0)) == 2 && len(e.subscribed(1)) == 2
+	}, time.Second, time.Millisecond)
+	if e.subscribed(1)[0] == "A" {
+		failed, survivor = 1, 0
+	}
+
+	e.mu.Lock()
+	e.noAck = true
+	_ = e.conns[failed].Close()
+	e.mu.Unlock()
+
+	// A and B are unsubscribed from the survivor, but not subscribed on the
+	// new connection before the survivor acknowledges it.
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"C", "D"}, e.subscribed(survivor)) && pool.Len() == 2
+	}, time.Second, time.Millisecond)
+	assert.Empty(t, e.subscribed(2))
+
+	// An update of A sent by the survivor before its response is dropped.
+	assert.NoError(t, e.write(survivor, connResponse{Type: "update", Channel: ChannelOrderBook, Market: "A", Data: []byte(`{"action":"update"}`)}))
+	assert.NoError(t, e.write(survivor, General{Type: "unsubscribed", Channel: ChannelOrderBook, Market: "A"}))
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual([]string{"A"}, e.subscribed(2))
+	}, time.Second, time.Millisecond)
+
+	assert.NoError(t, e.write(2, connResponse{Type: "partial", Channel: ChannelOrderBook, Market: "A", Data: []byte(`{"action":"partial"}`)}))
+	msg, err := recvData(pool)
+	assert.NoError(t, err)
+	assert.Equal(t, "partial", msg.(OrderBook).Type)
+	assert.Equal(t, "A", msg.(OrderBook).Market)
+}
@@ -0,0 +1,138 @@
+package stream
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Receiver is implemented by Conn, Recorder and Replayer so that consumers can
+// be fed from a live connection or a recording alike.
+type Receiver interface {
+	Recv() (interface{}, error)
+	RecvRaw() ([]byte, error)
+}
+
+// frame is one line of a recording.
+type frame struct {
+	Time int64           `json:"t"` // receive time in unix nanoseconds
+	Data json.RawMessage `json:"d"`
+}
+
+// Recorder wraps a Conn and writes every received frame to w as
+// gzip-compressed JSON lines.
+type Recorder struct {
+	conn *Conn
+
+	mu  sync.Mutex
+	gz  *gzip.Writer
+	enc *json.Encoder
+	now func() time.Time
+}
+
+func NewRecorder(conn *Conn, w io.Writer) *Recorder {
+	gz := gzip.NewWriter(w)
+	return &Recorder{conn: conn, gz: gz, enc: json.NewEncoder(gz), now: time.Now}
+}
+
+func (r *Recorder) Recv() (interface{}, error) {
+	msg, err := r.RecvRaw()
+	if err != nil {
+		return nil, err
+	}
+	return decode(msg)
+}
+
+func (r *Recorder) RecvRaw() ([]byte, error) {
+	msg, err := r.conn.RecvRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.enc.Encode(&frame{Time: r.now().UnixNano(), Data: msg}); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Flush writes buffered frames to the underlying writer.
+func (r *Recorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.gz.Flush()
+}
+
+// Close finishes the recording. It does not close the wrapped Conn.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.gz.Close()
+}
+
+// Replayer reads a recording made by Recorder and returns its frames with the
+// recorded spacing divided by speed. A speed of 0 replays without delay.
+// Recv and RecvRaw return io.EOF at the end of the recording.
+type Replayer struct {
+	gz    *gzip.Reader
+	dec   *json.Decoder
+	speed float64
+
+	first int64
+	start time.Time
+	last  time.Time
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewReplayer(r io.Reader, speed float64) (*Replayer, error) {
+	gz, err := gzip.NewReader(bufio.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	return &Replayer{gz: gz, dec: json.NewDecoder(gz), speed: speed, now: time.Now, sleep: time.Sleep}, nil
+}
+
+func (r *Replayer) Recv() (interface{}, error) {
+	msg, err := r.RecvRaw()
+	if err != nil {
+		return nil, err
+	}
+	return decode(msg)
+}
+
+func (r *Replayer) RecvRaw() ([]byte, error) {
+	var f frame
+	if err := r.dec.Decode(&f); err != nil {
+		return nil, err
+	}
+
+	if r.start.IsZero() {
+		r.first, r.start = f.Time, r.now()
+	} else if r.speed > 0 {
+		at := r.start.Add(time.Duration(float64(f.Time-r.first) / r.speed))
+		if d := at.Sub(r.now()); d > 0 {
+			r.sleep(d)
+		}
+	}
+
+	r.last = time.Unix(0, f.Time)
+	return f.Data, nil
+}
+
+// Time returns the recorded receive time of the last returned frame.
+func (r *Replayer) Time() time.Time {
+	return r.last
+}
+
+func (r *Replayer) Close() error {
+	return r.gz.Close()
+}
@@ -0,0 +1,69 @@
+package stream
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	conn, ws, teardown := setup()
+	defer teardown()
+
+	var buf bytes.Buffer
+	rec := NewRecorder(conn, &buf)
+	ts := time.Unix(1619858251, 0)
+	rec.now = func() time.Time {
+		ts = ts.Add(time.Second)
+		return ts
+	}
+
+	_ = ws.WriteJSON(&connResponse{Type: "pong"})
+	_ = ws.WriteJSON(&connResponse{Type: "update", Channel: ChannelTicker, Market: "BTC/USD", Data: []byte(`{"last":1234.56}`)})
+
+	resp, err := rec.Recv()
+	assert.NoError(t, err)
+	assert.IsType(t, Pong{}, resp)
+
+	resp, err = rec.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, 1234.56, resp.(Ticker).Data.Last)
+	assert.NoError(t, rec.Close())
+
+	t.Run("replay without delay", func(t *testing.T) {
+		rep, err := NewReplayer(bytes.NewReader(buf.Bytes()), 0)
+		assert.NoError(t, err)
+		rep.sleep = func(time.Duration) { t.Fatal("unexpected sleep") }
+
+		resp, err := rep.Recv()
+		assert.NoError(t, err)
+		assert.IsType(t, Pong{}, resp)
+		assert.Equal(t, int64(1619858252), rep.Time().Unix())
+
+		resp, err = rep.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, 1234.56, resp.(Ticker).Data.Last)
+
+		_, err = rep.Recv()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("replay accelerated", func(t *testing.T) {
+		rep, err := NewReplayer(bytes.NewReader(buf.Bytes()), 4)
+		assert.NoError(t, err)
+		now := time.Now()
+		rep.now = func() time.Time { return now }
+
+		var slept time.Duration
+		rep.sleep = func(d time.Duration) { slept += d }
+
+		for i := 0; i < 2; i++ {
+			_, err := rep.RecvRaw()
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, 250*time.Millisecond, slept)
+	})
+}
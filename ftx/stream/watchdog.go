@@ -0,0 +1,210 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WatchdogConfig configures the liveness checks of a Conn. Zero durations
+// disable the corresponding check.
+type WatchdogConfig struct {
+	// PongTimeout is the longest time to wait for the pong of a ping.
+	PongTimeout time.Duration
+	// ReadTimeout is the read deadline of every Recv. A Recv that times out
+	// fails and the connection can't be used anymore.
+	ReadTimeout time.Duration
+	// FeedTimeout is the longest time a subscription to a market may go
+	// without a message. Subscriptions without a market, or rejected with an
+	// error, are not checked.
+	FeedTimeout time.Duration
+
+	// OnStale is called by Watch when the connection or a feed goes stale.
+	OnStale func(Stale)
+	// CloseOnStale closes the connection when it or a feed goes stale, so a
+	// blocked Recv returns an error and the caller can reconnect.
+	CloseOnStale bool
+}
+
+// Stale describes a connection that didn't answer a ping, or a market feed
+// that didn't receive a message, within the configured timeout.
+type Stale struct {
+	Channel string // empty if the connection is stale
+	Market  string
+	Elapsed time.Duration // since the unanswered ping or the last message of the feed
+}
+
+// WithWatchdog tracks pongs and the messages of every subscription. See Conn.Watch.
+func WithWatchdog(cfg WatchdogConfig) Option {
+	return func(c *Conn) {
+		c.watch = &watchdog{cfg: cfg, feeds: make(map[feedKey]*feed)}
+	}
+}
+
+type feedKey struct {
+	channel string
+	market  string
+}
+
+type feed struct {
+	last     time.Time
+	reported bool
+}
+
+type watchdog struct {
+	cfg WatchdogConfig
+
+	mu           sync.Mutex
+	pingsSent    []time.Time // of the unanswered pings, oldest first
+	lastPong     time.Time
+	connReported bool
+	feeds        map[feedKey]*feed
+	unacked      []feedKey // subscriptions without a message yet, oldest first
+}
+
+func (w *watchdog) sent(req *connRequest, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch req.OP {
+	case "ping":
+		w.pingsSent = append(w.pingsSent, now)
+	case "subscribe":
+		if req.Market == "" {
+			return
+		}
+		k := feedKey{req.Channel, req.Market}
+		if _, ok := w.feeds[k]; !ok {
+			w.feeds[k] = &feed{last: now}
+			w.unacked = append(w.unacked, k)
+		}
+	case "unsubscribe":
+		w.remove(feedKey{req.Channel, req.Market})
+	}
+}
+
+// received handles msg, scanning only its header.
+func (w *watchdog) received(msg []byte, now time.Time) {
+	h, err := scanHeader(msg)
+	if err != nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	switch string(h.typ) {
+	case "pong":
+		// Pongs answer pings in order, the next ping is timed from now on.
+		w.lastPong = now
+		if len(w.pingsSent) > 0 {
+			w.pingsSent = w.pingsSent[1:]
+		}
+		w.connReported = false
+		return
+	case "error":
+		// Errors rejecting a subscription usually don't name it, so they are
+		// attributed to the oldest one without a message yet.
+		if len(h.channel) > 0 {
+			w.remove(feedKey{string(h.channel), string(h.market)})
+		} else if len(w.unacked) > 0 {
+			w.remove(w.unacked[0])
+		}
+		return
+	}
+
+	k := feedKey{string(h.channel), string(h.market)}
+	if f, ok := w.feeds[k]; ok {
+		f.last = now
+		f.reported = false
+		w.ack(k)
+	}
+}
+
+// ack removes k from the subscriptions without a message yet.
+func (w *watchdog) ack(k feedKey) {
+	for i, v := range w.unacked {
+		if v == k {
+			w.unacked = append(w.unacked[:i], w.unacked[i+1:]...)
+			return
+		}
+	}
+}
+
+func (w *watchdog) remove(k feedKey) {
+	delete(w.feeds, k)
+	w.ack(k)
+}
+
+// stale returns what went stale since the last call.
+func (w *watchdog) stale(now time.Time) []Stale {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var out []Stale
+	if w.cfg.PongTimeout > 0 && len(w.pingsSent) > 0 && !w.connReported {
+		if elapsed := now.Sub(w.pingsSent[0]); elapsed > w.cfg.PongTimeout {
+			w.connReported = true
+			out = append(out, Stale{Elapsed: elapsed})
+		}
+	}
+	if w.cfg.FeedTimeout > 0 {
+		for k, f := range w.feeds {
+			if elapsed := now.Sub(f.last); elapsed > w.cfg.FeedTimeout && !f.reported {
+				f.reported = true
+				out = append(out, Stale{Channel: k.channel, Market: k.market, Elapsed: elapsed})
+			}
+		}
+	}
+	return out
+}
+
+// interval returns how often to check, a quarter of the shortest timeout.
+func (w *watchdog) interval() time.Duration {
+	var d time.Duration
+	for _, t := range []time.Duration{w.cfg.PongTimeout, w.cfg.FeedTimeout} {
+		if t > 0 && (d == 0 || t < d) {
+			d = t
+		}
+	}
+	return d / 4
+}
+
+// LastPong returns when the last pong was received. It requires WithWatchdog.
+func (c *Conn) LastPong() time.Time {
+	if c.watch == nil {
+		return time.Time{}
+	}
+	c.watch.mu.Lock()
+	defer c.watch.mu.Unlock()
+	return c.watch.lastPong
+}
+
+// Watch checks the pong and feed timeouts of WithWatchdog in the background
+// until ctx is done or the connection is closed because of CloseOnStale. Pings
+// are not sent by Watch, see PingRegular.
+func (c *Conn) Watch(ctx context.Context) {
+	if c.watch == nil || c.watch.interval() <= 0 {
+		return
+	}
+	go func() {
+		t := time.NewTicker(c.watch.interval())
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C:
+				stale := c.watch.stale(now)
+				for _, s := range stale {
+					if c.watch.cfg.OnStale != nil {
+						c.watch.cfg.OnStale(s)
+					}
+				}
+				if len(stale) > 0 && c.watch.cfg.CloseOnStale {
+					_ = c.Close()
+					return
+				}
+			}
+		}
+	}()
+}
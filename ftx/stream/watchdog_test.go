@@ -0,0 +1,120 @@
+package stream
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog_Pong(t *testing.T) {
+	w := &watchdog{cfg: WatchdogConfig{PongTimeout: time.Second}, feeds: make(map[feedKey]*feed)}
+	start := time.Now()
+
+	w.sent(&connRequest{OP: "ping"}, start)
+	w.sent(&connRequest{OP: "ping"}, start.Add(500*time.Millisecond))
+
+	assert.Empty(t, w.stale(start.Add(time.Second)))
+	assert.Equal(t, []Stale{{Elapsed: 2 * time.Second}}, w.stale(start.Add(2*time.Second)))
+	assert.Empty(t, w.stale(start.Add(3*time.Second)), "reported once")
+
+	w.received([]byte(`{"type":"pong"}`), start.Add(4*time.Second))
+	assert.Equal(t, start.Add(4*time.Second), w.lastPong)
+
+	// The second ping is still unanswered.
+	assert.Equal(t, []Stale{{Elapsed: 4500 * time.Millisecond}}, w.stale(start.Add(5*time.Second)))
+
+	w.received([]byte(`{"type":"pong"}`), start.Add(6*time.Second))
+	assert.Empty(t, w.stale(start.Add(10*time.Second)))
+}
+
+func TestWatchdog_Feed(t *testing.T) {
+	w := &watchdog{cfg: WatchdogConfig{FeedTimeout: time.Second}, feeds: make(map[feedKey]*feed)}
+	start := time.Now()
+
+	w.sent(&connRequest{OP: "subscribe", Channel: ChannelTrades, Market: "BTC-PERP"}, start)
+	w.sent(&connRequest{OP: "subscribe", Channel: ChannelTicker, Market: "ETH-PERP"}, start)
+	w.sent(&connRequest{OP: "subscribe", Channel: ChannelFills}, start)
+
+	w.received([]byte(`{"type":"update","channel":"trades","market":"BTC-PERP","data":[]}`), start.Add(1500*time.Millisecond))
+
+	assert.Equal(t, []Stale{{Channel: ChannelTicker, Market: "ETH-PERP", Elapsed: 2 * time.Second}}, w.stale(start.Add(2*time.Second)))
+	assert.Equal(t, []Stale{{Channel: ChannelTrades, Market: "BTC-PERP", Elapsed: 1600 * time.Millisecond}}, w.stale(start.Add(3100*time.Millisecond)))
+
+	w.received([]byte(`{"type":"update","channel":"ticker","market":"ETH-PERP","data":{}}`), start.Add(4*time.Second))
+	w.sent(&connRequest{OP: "unsubscribe", Channel: ChannelTrades, Market: "BTC-PERP"}, start.Add(4*time.Second))
+
+	assert.Empty(t, w.stale(start.Add(4500*time.Millisecond)))
+	assert.Equal(t, []Stale{{Channel: ChannelTicker, Market: "ETH-PERP", Elapsed: 2 * time.Second}}, w.stale(start.Add(6*time.Second)))
+}
+
+func TestWatchdog_FeedError(t *testing.T) {
+	w := &watchdog{cfg: WatchdogConfig{FeedTimeout: time.Second}, feeds: make(map[feedKey]*feed)}
+	start := time.Now()
+
+	w.sent(&connRequest{OP: "subscribe", Channel: ChannelTrades, Market: "BTC-PERP"}, start)
+	w.received([]byte(`{"type":"subscribed","channel":"trades","market":"BTC-PERP"}`), start)
+	w.sent(&connRequest{OP: "subscribe", Channel: ChannelTicker, Market: "NOPE"}, start)
+	w.sent(&connRequest{OP: "subscribe", Channel: ChannelOrderBook, Market: "ETH-PERP"}, start)
+
+	w.received([]byte(`{"type":"error","code":404,"msg":"No such market: NOPE"}`), start)
+
+	assert.Equal(t, []Stale{
+		{Channel: ChannelOrderBook, Market: "ETH-PERP", Elapsed: 2 * time.Second},
+		{Channel: ChannelTrades, Market: "BTC-PERP", Elapsed: 2 * time.Second},
+	}, sortStale(w.stale(start.Add(2*time.Second))))
+	assert.Equal(t, []feedKey{{ChannelOrderBook, "ETH-PERP"}}, w.unacked)
+
+	w.received([]byte(`{"type":"error","code":400,"channel":"orderbook","market":"ETH-PERP","msg":"Already subscribed"}`), start)
+	assert.Len(t, w.feeds, 1)
+	assert.Empty(t, w.unacked)
+}
+
+func sortStale(stale []Stale) []Stale {
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Channel < stale[j].Channel })
+	return stale
+}
+
+func TestConn_Watch(t *testing.T) {
+	conn, _, teardown := setup()
+	defer teardown()
+
+	stale := make(chan Stale, 1)
+	WithWatchdog(WatchdogConfig{
+		PongTimeout:  20 * time.Millisecond,
+		OnStale:      func(s Stale) { stale <- s },
+		CloseOnStale: true,
+	})(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	conn.Watch(ctx)
+
+	// The echo server answers a ping with the ping itself, never with a pong.
+	assert.NoError(t, conn.Ping())
+	_, err := conn.RecvRaw()
+	assert.NoError(t, err)
+
+	s := <-stale
+	assert.Equal(t, "", s.Channel)
+	assert.True(t, s.Elapsed > 20*time.Millisecond)
+
+	_, err = conn.RecvRaw()
+	assert.Error(t, err, "closed on stale")
+}
+
+func TestConn_ReadTimeout(t *testing.T) {
+	conn, _, teardown := setup()
+	defer teardown()
+
+	WithWatchdog(WatchdogConfig{ReadTimeout: 10 * time.Millisecond})(conn)
+
+	_, err := conn.RecvRaw()
+
+	var netErr net.Error
+	assert.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+}
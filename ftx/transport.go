@@ -0,0 +1,65 @@
+package ftx
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Transport sends a signed request and reads its response. *fasthttp.Client
+// implements it and is the default.
+type Transport interface {
+	Do(req *fasthttp.Request, resp *fasthttp.Response) error
+}
+
+// HTTPTransport sends requests with a net/http client, e.g. to go through
+// HTTP_PROXY, an httptest.Server or net/http based tracing.
+type HTTPTransport struct {
+	Client *http.Client
+}
+
+// NewHTTPTransport returns a Transport sending requests with client, or with
+// a client using http.DefaultTransport if client is nil.
+func NewHTTPTransport(client *http.Client) *HTTPTransport {
+	if client == nil {
+		client = &http.Client{Timeout: 6 * time.Second}
+	}
+	return &HTTPTransport{Client: client}
+}
+
+func (t *HTTPTransport) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	hreq, err := http.NewRequest(string(req.Header.Method()), req.URI().String(), bytes.NewReader(req.Body()))
+	if err != nil {
+		return err
+	}
+	req.Header.VisitAll(func(k, v []byte) {
+		key := string(k)
+		if strings.EqualFold(key, fasthttp.HeaderHost) || strings.EqualFold(key, fasthttp.HeaderContentLength) {
+			return
+		}
+		hreq.Header.Add(key, string(v))
+	})
+	if hreq.Header.Get(fasthttp.HeaderUserAgent) == "" {
+		hreq.Header.Set(fasthttp.HeaderUserAgent, userAgent)
+	}
+
+	hresp, err := t.Client.Do(hreq)
+	if err != nil {
+		return err
+	}
+	defer hresp.Body.Close()
+
+	resp.Reset()
+	resp.SetStatusCode(hresp.StatusCode)
+	for k, vs := range hresp.Header {
+		for _, v := range vs {
+			resp.Header.Add(k, v)
+		}
+	}
+	_, err = io.Copy(resp.BodyWriter(), hresp.Body)
+	return err
+}
@@ -0,0 +1,89 @@
+package ftx
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestHTTPTransport(t *testing.T) {
+	type captured struct {
+		method, uri, body, contentType string
+		key, sign, ts, subaccount      string
+	}
+
+	ch := make(chan captured, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		ch <- captured{
+			method:      r.Method,
+			uri:         r.URL.RequestURI(),
+			body:        string(body),
+			contentType: r.Header.Get("Content-Type"),
+			key:         r.Header.Get(HeaderKey),
+			sign:        r.Header.Get(HeaderSign),
+			ts:          r.Header.Get(HeaderTS),
+			subaccount:  r.Header.Get(HeaderSubaccount),
+		}
+		w.Header().Set("X-Test", "ok")
+		_, _ = w.Write([]byte(`{"success":true,"result":{"foo":"bar"}}`))
+	}))
+	defer srv.Close()
+
+	unixTime = func() int64 { return 1588591511721 }
+
+	c := New(WithAuth("api-key", "api-secret"), WithSubaccount("sub"), WithHTTPClient(srv.Client()))
+	c.baseURL = srv.URL
+
+	var out struct{ Foo string }
+	err := c.DoPrivate(srv.URL+"/orders?market=BTC-PERP", http.MethodPost, map[string]int{"size": 1}, &out)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", out.Foo)
+
+	got := <-ch
+	assert.Equal(t, http.MethodPost, got.method)
+	assert.Equal(t, "/orders?market=BTC-PERP", got.uri)
+	assert.JSONEq(t, `{"size":1}`, got.body)
+	assert.Equal(t, "application/json", got.contentType)
+	assert.Equal(t, "api-key", got.key)
+	assert.Equal(t, "1588591511721", got.ts)
+	assert.Equal(t, "sub", got.subaccount)
+
+	// The signature is the same as when sending with fasthttp.
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetRequestURI(srv.URL + "/orders?market=BTC-PERP")
+	req.Header.SetMethod(http.MethodPost)
+	req.SetBodyString(got.body)
+	assert.NoError(t, c.auth(req))
+	assert.Equal(t, string(req.Header.Peek(HeaderSign)), got.sign)
+}
+
+func TestHTTPTransport_Response(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, userAgent, r.UserAgent())
+		w.Header().Set("X-Test", "ok")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("body"))
+	}))
+	defer srv.Close()
+
+	req, resp := fasthttp.AcquireRequest(), fasthttp.AcquireResponse()
+	defer func() {
+		fasthttp.ReleaseRequest(req)
+		fasthttp.ReleaseResponse(resp)
+	}()
+	req.SetRequestURI(srv.URL)
+
+	err := NewHTTPTransport(srv.Client()).Do(req, resp)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode())
+	assert.Equal(t, "ok", string(resp.Header.Peek("X-Test")))
+	assert.Equal(t, "body", string(resp.Body()))
+}